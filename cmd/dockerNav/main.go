@@ -2,8 +2,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"golang.org/x/term"
 
@@ -11,7 +13,21 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// fileList collects repeated -f flags
+type fileList []string
+
+func (f *fileList) String() string { return strings.Join(*f, ",") }
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 func main() {
+	var composeFiles fileList
+	flag.Var(&composeFiles, "f", "compose file of a project to manage (repeat to merge several files)")
+	flag.Parse()
+
 	// Query the actual terminal size.
 	width, height, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
@@ -20,7 +36,7 @@ func main() {
 	}
 
 	// Initialize the main model with actual dimensions.
-	m := ui.NewMainModel(width, height)
+	m := ui.NewMainModel(width, height, composeFiles)
 	
 	// Initialize the Bubble Tea program with options for proper window sizing
 	p := tea.NewProgram(
@@ -0,0 +1,302 @@
+// Package buildcontext packs a directory into the tar archive sent to the
+// daemon as the context of an image build, leaving out the paths listed in
+// its .dockerignore file.
+package buildcontext
+
+import (
+	"archive/tar"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile is the name of the file listing the paths to leave out
+const IgnoreFile = ".dockerignore"
+
+// outsideDockerfile is the name given to a Dockerfile that lives outside the
+// context directory, like the Docker CLI does
+const outsideDockerfile = ".dockerfile.dockernav"
+
+// pattern is a line of a .dockerignore file
+type pattern struct {
+	text      string
+	exclusion bool // a line starting with ! re-includes matching paths
+	re        *regexp.Regexp
+}
+
+// Matcher decides which paths of a context are left out
+type Matcher struct {
+	patterns   []pattern
+	exclusions bool
+}
+
+// ReadIgnoreFile reads the .dockerignore file of a context directory. A
+// missing file ignores nothing.
+func ReadIgnoreFile(dir string) (*Matcher, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Matcher{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", IgnoreFile, err)
+	}
+	return NewMatcher(lines)
+}
+
+// NewMatcher parses .dockerignore lines. Empty lines and lines starting with
+// # are skipped.
+func NewMatcher(lines []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := pattern{}
+		if strings.HasPrefix(line, "!") {
+			p.exclusion = true
+			m.exclusions = true
+			line = strings.TrimSpace(line[1:])
+		}
+		line = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(line)), "/")
+		if line == "" {
+			continue
+		}
+		re, err := compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: bad pattern %q: %w", IgnoreFile, line, err)
+		}
+		p.text = line
+		p.re = re
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+// compile turns a pattern into a regular expression. * and ? match within a
+// path element, ** matches any number of elements.
+func compile(p string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				i++
+				// **/ also matches no directory at all
+				if i+1 < len(p) && p[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := p[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case '\\':
+			if i+1 < len(p) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(p[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Excluded reports whether a slash-separated path relative to the context is
+// left out. A pattern matching a parent directory matches the path as well,
+// and the last matching line wins.
+func (m *Matcher) Excluded(rel string) bool {
+	excluded := false
+	for _, p := range m.patterns {
+		if p.exclusion == !excluded {
+			// This line cannot change the outcome
+			continue
+		}
+		if matchesOrParent(p.re, rel) {
+			excluded = !p.exclusion
+		}
+	}
+	return excluded
+}
+
+// matchesOrParent reports whether re matches rel or one of its parents
+func matchesOrParent(re *regexp.Regexp, rel string) bool {
+	for p := rel; ; {
+		if re.MatchString(p) {
+			return true
+		}
+		i := strings.LastIndex(p, "/")
+		if i < 0 {
+			return false
+		}
+		p = p[:i]
+	}
+}
+
+// Tar packs the context directory into a tar stream. dockerfile is the path
+// of the Dockerfile relative to the context, or an absolute path; the
+// returned name is the one to pass to the build. The Dockerfile and the
+// .dockerignore file are always sent, as the daemon needs them.
+func Tar(dir, dockerfile string) (io.ReadCloser, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return nil, "", err
+	} else if !info.IsDir() {
+		return nil, "", fmt.Errorf("%s is not a directory", dir)
+	}
+
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	dockerfilePath := dockerfile
+	if !filepath.IsAbs(dockerfilePath) {
+		dockerfilePath = filepath.Join(dir, dockerfile)
+	}
+	if _, err := os.Stat(dockerfilePath); err != nil {
+		return nil, "", err
+	}
+
+	matcher, err := ReadIgnoreFile(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// A Dockerfile outside the context is added under a name of its own
+	name, err := filepath.Rel(dir, dockerfilePath)
+	if err != nil {
+		return nil, "", err
+	}
+	name = filepath.ToSlash(name)
+	if name == ".." || strings.HasPrefix(name, "../") {
+		name = outsideDockerfile
+	}
+	// The kept files are added after the walk, which may skip their
+	// directories
+	keep := map[string]string{name: dockerfilePath}
+	if _, err := os.Stat(filepath.Join(dir, IgnoreFile)); err == nil {
+		keep[IgnoreFile] = filepath.Join(dir, IgnoreFile)
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(writer)
+		err := writeDir(tw, dir, matcher, keep)
+		for _, kept := range []string{name, IgnoreFile} {
+			if p, ok := keep[kept]; ok && err == nil {
+				err = addFile(tw, p, kept)
+			}
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	return reader, name, nil
+}
+
+// writeDir adds the files of dir that the matcher does not leave out, except
+// the kept ones
+func writeDir(tw *tar.Writer, dir string, matcher *Matcher, keep map[string]string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		if _, ok := keep[rel]; ok {
+			return nil
+		}
+		if matcher.Excluded(rel) {
+			// Without ! lines nothing below an excluded directory comes back
+			if d.IsDir() && !matcher.exclusions {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return addFile(tw, p, rel)
+	})
+}
+
+// addFile writes a file, directory or symlink to the archive under name
+func addFile(tw *tar.Writer, p, name string) error {
+	info, err := os.Lstat(p)
+	if err != nil {
+		return err
+	}
+
+	link := ""
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if link, err = os.Readlink(p); err != nil {
+			return err
+		}
+	}
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	// Ownership on the host means nothing in the image
+	hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
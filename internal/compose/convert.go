@@ -0,0 +1,309 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+// Labels Docker Compose puts on the resources it creates
+const (
+	LabelProject     = "com.docker.compose.project"
+	LabelService     = "com.docker.compose.service"
+	LabelNumber      = "com.docker.compose.container-number"
+	LabelOneoff      = "com.docker.compose.oneoff"
+	LabelWorkingDir  = "com.docker.compose.project.working_dir"
+	LabelConfigFiles = "com.docker.compose.project.config_files"
+	LabelNetwork     = "com.docker.compose.network"
+	LabelVolume      = "com.docker.compose.volume"
+)
+
+// defaultNetwork is the network services without a networks key join
+const defaultNetwork = "default"
+
+// ContainerSpec holds everything needed to create a service container
+type ContainerSpec struct {
+	Name       string
+	Config     *container.Config
+	HostConfig *container.HostConfig
+	// Primary is the network the container is created on, the others in
+	// Networks are connected after creation
+	Primary  string
+	Networks map[string]*network.EndpointSettings
+}
+
+// NetworkName returns the Docker network name for a compose network key
+func (p *Project) NetworkName(key string) string {
+	if nw, ok := p.Networks[key]; ok {
+		if nw.Name != "" {
+			return nw.Name
+		}
+		if nw.External {
+			return key
+		}
+	}
+	return p.Name + "_" + key
+}
+
+// VolumeName returns the Docker volume name for a compose volume key
+func (p *Project) VolumeName(key string) string {
+	if vol, ok := p.Volumes[key]; ok {
+		if vol.Name != "" {
+			return vol.Name
+		}
+		if vol.External {
+			return key
+		}
+	}
+	return p.Name + "_" + key
+}
+
+// ServiceNetworks returns the compose network keys a service is attached to
+func (p *Project) ServiceNetworks(svc Service) []string {
+	if len(svc.Networks) == 0 {
+		return []string{defaultNetwork}
+	}
+	keys := make([]string, 0, len(svc.Networks))
+	for key := range svc.Networks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ContainerName returns the name of a replica of a service
+func (p *Project) ContainerName(service string, number int) string {
+	if svc, ok := p.Services[service]; ok && svc.ContainerName != "" {
+		return svc.ContainerName
+	}
+	return fmt.Sprintf("%s-%s-%d", p.Name, service, number)
+}
+
+// ServiceNames returns the services ordered so dependencies come first
+func (p *Project) ServiceNames() []string {
+	names := make([]string, 0, len(p.Services))
+	for name := range p.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ordered []string
+	visited := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, dep := range p.Services[name].DependsOn {
+			if _, ok := p.Services[dep]; ok {
+				visit(dep)
+			}
+		}
+		ordered = append(ordered, name)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+	return ordered
+}
+
+// Labels returns the compose labels of a replica of a service
+func (p *Project) Labels(service string, number int) map[string]string {
+	labels := map[string]string{}
+	for k, v := range p.Services[service].Labels {
+		labels[k] = v
+	}
+	labels[LabelProject] = p.Name
+	labels[LabelService] = service
+	labels[LabelNumber] = strconv.Itoa(number)
+	labels[LabelOneoff] = "False"
+	labels[LabelWorkingDir] = p.WorkingDir
+	labels[LabelConfigFiles] = strings.Join(p.ConfigFiles, ",")
+	return labels
+}
+
+// Environment returns the sorted environment of a service, with values from
+// environment overriding those from env_file
+func (p *Project) Environment(svc Service) ([]string, error) {
+	fromFiles, err := p.ReadEnvFiles(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	env := MappingOrList{}
+	for _, kv := range fromFiles {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	for k, v := range svc.Environment {
+		env[k] = v
+	}
+	return env.List(), nil
+}
+
+// Ports parses the published and exposed ports of a service
+func (p *Project) Ports(svc Service) (nat.PortSet, nat.PortMap, error) {
+	specs := make([]string, 0, len(svc.Ports)+len(svc.Expose))
+	for _, port := range svc.Ports {
+		specs = append(specs, string(port))
+	}
+	exposed, bindings, err := nat.ParsePortSpecs(specs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, expose := range svc.Expose {
+		proto, port := nat.SplitProtoPort(expose)
+		natPort, err := nat.NewPort(proto, port)
+		if err != nil {
+			return nil, nil, err
+		}
+		exposed[natPort] = struct{}{}
+	}
+	return exposed, bindings, nil
+}
+
+// Mounts converts service volumes into binds and anonymous volumes
+func (p *Project) Mounts(svc Service) ([]string, map[string]struct{}) {
+	var binds []string
+	anonymous := map[string]struct{}{}
+
+	for _, spec := range svc.Volumes {
+		parts := strings.Split(string(spec), ":")
+		if len(parts) == 1 {
+			anonymous[parts[0]] = struct{}{}
+			continue
+		}
+
+		source := parts[0]
+		if strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") {
+			source = p.resolvePath(source)
+		} else {
+			source = p.VolumeName(source)
+		}
+		binds = append(binds, strings.Join(append([]string{source}, parts[1:]...), ":"))
+	}
+	return binds, anonymous
+}
+
+// RestartPolicy parses the restart option of a service
+func RestartPolicy(restart string) (container.RestartPolicy, error) {
+	if restart == "" {
+		return container.RestartPolicy{Name: container.RestartPolicyDisabled}, nil
+	}
+
+	name, count, _ := strings.Cut(restart, ":")
+	policy := container.RestartPolicy{Name: container.RestartPolicyMode(name)}
+	if count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return policy, fmt.Errorf("invalid restart policy %q", restart)
+		}
+		policy.MaximumRetryCount = n
+	}
+	return policy, container.ValidateRestartPolicy(policy)
+}
+
+// Tmpfs converts tmpfs entries of the form path[:options] into a map
+func Tmpfs(entries []string) map[string]string {
+	if len(entries) == 0 {
+		return nil
+	}
+	tmpfs := map[string]string{}
+	for _, entry := range entries {
+		path, opts, _ := strings.Cut(entry, ":")
+		tmpfs[path] = opts
+	}
+	return tmpfs
+}
+
+// ContainerSpec builds the container configuration of a replica of a service
+func (p *Project) ContainerSpec(service string, number int) (*ContainerSpec, error) {
+	svc, ok := p.Services[service]
+	if !ok {
+		return nil, fmt.Errorf("service %s is not defined", service)
+	}
+	if svc.Image == "" {
+		return nil, fmt.Errorf("service %s has no image; building is not supported", service)
+	}
+	if svc.ContainerName != "" && number > 1 {
+		return nil, fmt.Errorf("service %s sets container_name, so it cannot have more than one replica", service)
+	}
+
+	env, err := p.Environment(svc)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", service, err)
+	}
+	exposed, bindings, err := p.Ports(svc)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", service, err)
+	}
+	restart, err := RestartPolicy(svc.Restart)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", service, err)
+	}
+	binds, anonymous := p.Mounts(svc)
+
+	config := &container.Config{
+		Image:        svc.Image,
+		Hostname:     svc.Hostname,
+		User:         svc.User,
+		WorkingDir:   svc.WorkingDir,
+		Env:          env,
+		Cmd:          []string(svc.Command),
+		Entrypoint:   []string(svc.Entrypoint),
+		ExposedPorts: exposed,
+		Volumes:      anonymous,
+		Labels:       p.Labels(service, number),
+		Tty:          svc.Tty,
+		OpenStdin:    svc.StdinOpen,
+		StopSignal:   svc.StopSignal,
+	}
+
+	hostConfig := &container.HostConfig{
+		Binds:          binds,
+		PortBindings:   bindings,
+		RestartPolicy:  restart,
+		Privileged:     svc.Privileged,
+		ReadonlyRootfs: svc.ReadOnly,
+		CapAdd:         svc.CapAdd,
+		CapDrop:        svc.CapDrop,
+		ExtraHosts:     svc.ExtraHosts,
+		DNS:            svc.DNS,
+		Tmpfs:          Tmpfs(svc.Tmpfs),
+	}
+
+	spec := &ContainerSpec{
+		Name:       p.ContainerName(service, number),
+		Config:     config,
+		HostConfig: hostConfig,
+		Networks:   map[string]*network.EndpointSettings{},
+	}
+
+	for i, key := range p.ServiceNetworks(svc) {
+		name := p.NetworkName(key)
+		endpoint := &network.EndpointSettings{Aliases: []string{service}}
+		if settings := svc.Networks[key]; settings != nil {
+			endpoint.Aliases = append(endpoint.Aliases, settings.Aliases...)
+			if settings.IPv4Address != "" || settings.IPv6Address != "" {
+				endpoint.IPAMConfig = &network.EndpointIPAMConfig{
+					IPv4Address: settings.IPv4Address,
+					IPv6Address: settings.IPv6Address,
+				}
+			}
+		}
+		spec.Networks[name] = endpoint
+		if i == 0 {
+			spec.Primary = name
+		}
+	}
+	hostConfig.NetworkMode = container.NetworkMode(spec.Primary)
+
+	return spec, nil
+}
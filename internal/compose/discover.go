@@ -0,0 +1,164 @@
+package compose
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// ServiceState compares a service definition with its containers
+type ServiceState struct {
+	Name       string
+	Containers []container.Summary
+	Running    int
+	// Desired is the declared replica count, or -1 when the service is not
+	// in the compose files
+	Desired int
+}
+
+// Missing reports whether fewer replicas run than declared
+func (s *ServiceState) Missing() bool {
+	return s.Desired >= 0 && s.Running < s.Desired
+}
+
+// ProjectState is a compose project discovered from container labels
+type ProjectState struct {
+	Name        string
+	WorkingDir  string
+	ConfigFiles []string
+	Services    []*ServiceState
+	// Project is the loaded definition, nil when LoadError is set
+	Project   *Project
+	LoadError error
+}
+
+// Running returns the running and total number of containers
+func (p *ProjectState) Running() (running, total int) {
+	for _, svc := range p.Services {
+		running += svc.Running
+		total += len(svc.Containers)
+	}
+	return running, total
+}
+
+// Missing returns the services running fewer replicas than declared
+func (p *ProjectState) Missing() []string {
+	var names []string
+	for _, svc := range p.Services {
+		if svc.Missing() {
+			names = append(names, svc.Name)
+		}
+	}
+	return names
+}
+
+// Discover finds compose projects from the labels of existing containers
+// and loads their compose files to compare declared and running services.
+// When configFiles is given, the project they define is included even if
+// none of its containers exist, and those files take precedence over the
+// ones recorded in the labels.
+func Discover(ctx context.Context, cli *client.Client, configFiles []string) ([]*ProjectState, error) {
+	containers, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", LabelProject)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byProject := map[string]*ProjectState{}
+	for _, c := range containers {
+		name := c.Labels[LabelProject]
+		state, ok := byProject[name]
+		if !ok {
+			state = &ProjectState{Name: name}
+			byProject[name] = state
+		}
+		if state.WorkingDir == "" {
+			state.WorkingDir = c.Labels[LabelWorkingDir]
+		}
+		if len(state.ConfigFiles) == 0 && c.Labels[LabelConfigFiles] != "" {
+			state.ConfigFiles = strings.Split(c.Labels[LabelConfigFiles], ",")
+		}
+		state.addContainer(c)
+	}
+
+	var explicit *Project
+	if len(configFiles) > 0 {
+		project, err := Load("", "", configFiles)
+		if err != nil {
+			return nil, err
+		}
+		explicit = project
+		if _, ok := byProject[project.Name]; !ok {
+			byProject[project.Name] = &ProjectState{Name: project.Name}
+		}
+	}
+
+	projects := make([]*ProjectState, 0, len(byProject))
+	for _, state := range byProject {
+		if explicit != nil && state.Name == explicit.Name {
+			state.use(explicit)
+		} else {
+			state.load()
+		}
+		sort.Slice(state.Services, func(i, j int) bool {
+			return state.Services[i].Name < state.Services[j].Name
+		})
+		projects = append(projects, state)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+	return projects, nil
+}
+
+// service returns the state of a service, adding it when needed
+func (p *ProjectState) service(name string) *ServiceState {
+	for _, svc := range p.Services {
+		if svc.Name == name {
+			return svc
+		}
+	}
+	svc := &ServiceState{Name: name, Desired: -1}
+	p.Services = append(p.Services, svc)
+	return svc
+}
+
+// addContainer records a container under its service
+func (p *ProjectState) addContainer(c container.Summary) {
+	svc := p.service(c.Labels[LabelService])
+	svc.Containers = append(svc.Containers, c)
+	if c.State == "running" {
+		svc.Running++
+	}
+}
+
+// load reads the compose files of the project and records the declared
+// replica count of each service
+func (p *ProjectState) load() {
+	if len(p.ConfigFiles) == 0 {
+		return
+	}
+	project, err := Load(p.Name, p.WorkingDir, p.ConfigFiles)
+	if err != nil {
+		p.LoadError = err
+		return
+	}
+	p.use(project)
+}
+
+// use records a loaded project definition and the declared replica count
+// of each service
+func (p *ProjectState) use(project *Project) {
+	p.Project = project
+	p.WorkingDir = project.WorkingDir
+	p.ConfigFiles = project.ConfigFiles
+	for name, svc := range project.Services {
+		p.service(name).Desired = svc.Replicas()
+	}
+}
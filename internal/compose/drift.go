@@ -0,0 +1,316 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// Difference is a single mismatch between a declared service and one of
+// its containers
+type Difference struct {
+	Container string
+	Field     string
+	Declared  string
+	Actual    string
+}
+
+// ServiceDrift lists how the containers of a service differ from the
+// compose files
+type ServiceDrift struct {
+	Service     string
+	Differences []Difference
+	// Missing holds the replica numbers that have no container
+	Missing []int
+	// Extra holds containers numbered above the declared replica count
+	Extra []string
+	// Undeclared is set for services that are not in the compose files
+	Undeclared bool
+}
+
+// OutOfDate reports whether the service needs to be recreated or scaled
+func (d ServiceDrift) OutOfDate() bool {
+	return len(d.Differences) > 0 || len(d.Missing) > 0 || len(d.Extra) > 0
+}
+
+// Drift compares the services of a project with their running containers
+func Drift(ctx context.Context, cli *client.Client, p *Project) ([]ServiceDrift, error) {
+	containers, err := projectContainers(ctx, cli, p.Name)
+	if err != nil {
+		return nil, err
+	}
+	byService := map[string][]container.Summary{}
+	for _, c := range containers {
+		service := c.Labels[LabelService]
+		byService[service] = append(byService[service], c)
+	}
+
+	var drifts []ServiceDrift
+	for _, service := range p.ServiceNames() {
+		drift, err := serviceDrift(ctx, cli, p, service, byService[service])
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, drift)
+		delete(byService, service)
+	}
+
+	// Whatever is left runs under the project but is no longer declared
+	undeclared := make([]string, 0, len(byService))
+	for service := range byService {
+		undeclared = append(undeclared, service)
+	}
+	sort.Strings(undeclared)
+	for _, service := range undeclared {
+		drift := ServiceDrift{Service: service, Undeclared: true}
+		for _, c := range byService[service] {
+			drift.Extra = append(drift.Extra, DisplayName(c))
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// serviceDrift compares a service definition with its containers
+func serviceDrift(ctx context.Context, cli *client.Client, p *Project, service string, containers []container.Summary) (ServiceDrift, error) {
+	svc := p.Services[service]
+	drift := ServiceDrift{Service: service}
+
+	env, err := p.Environment(svc)
+	if err != nil {
+		return drift, fmt.Errorf("service %s: %w", service, err)
+	}
+	_, bindings, err := p.Ports(svc)
+	if err != nil {
+		return drift, fmt.Errorf("service %s: %w", service, err)
+	}
+	declaredPorts := formatPortMap(bindings)
+
+	replicas := svc.Replicas()
+	present := map[int]bool{}
+	for _, c := range containers {
+		name := DisplayName(c)
+		number := ContainerNumber(c)
+		if number > replicas {
+			drift.Extra = append(drift.Extra, name)
+			continue
+		}
+		present[number] = true
+
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return drift, err
+		}
+
+		if declared, actual := normalizeImage(svc.Image), normalizeImage(info.Config.Image); svc.Image != "" && declared != actual {
+			drift.Differences = append(drift.Differences, Difference{
+				Container: name,
+				Field:     "image",
+				Declared:  svc.Image,
+				Actual:    info.Config.Image,
+			})
+		}
+
+		// Variables set by the image are not part of the compose file
+		var imageEnv []string
+		if img, err := cli.ImageInspect(ctx, info.Image); err == nil && img.Config != nil {
+			imageEnv = img.Config.Env
+		}
+		for _, d := range envDifferences(env, info.Config.Env, imageEnv) {
+			d.Container = name
+			drift.Differences = append(drift.Differences, d)
+		}
+
+		if actual := formatPortMap(info.HostConfig.PortBindings); actual != declaredPorts {
+			drift.Differences = append(drift.Differences, Difference{
+				Container: name,
+				Field:     "ports",
+				Declared:  declaredPorts,
+				Actual:    actual,
+			})
+		}
+	}
+
+	for number := 1; number <= replicas; number++ {
+		if !present[number] {
+			drift.Missing = append(drift.Missing, number)
+		}
+	}
+	return drift, nil
+}
+
+// normalizeImage expands an image reference to its fully qualified form so
+// nginx and docker.io/library/nginx:latest compare equal
+func normalizeImage(ref string) string {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return ref
+	}
+	return reference.TagNameOnly(named).String()
+}
+
+// envDifferences compares declared and actual KEY=VALUE lists. Actual
+// variables that only repeat the image defaults are ignored.
+func envDifferences(declared, actual, image []string) []Difference {
+	toMap := func(list []string) map[string]string {
+		m := make(map[string]string, len(list))
+		for _, kv := range list {
+			k, v, _ := strings.Cut(kv, "=")
+			m[k] = v
+		}
+		return m
+	}
+	want, have, defaults := toMap(declared), toMap(actual), toMap(image)
+
+	keys := make([]string, 0, len(want)+len(have))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range have {
+		if _, ok := want[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []Difference
+	for _, k := range keys {
+		wantValue, declaredOK := want[k]
+		haveValue, actualOK := have[k]
+		switch {
+		case declaredOK && !actualOK:
+			diffs = append(diffs, Difference{Field: "env " + k, Declared: wantValue, Actual: "(unset)"})
+		case declaredOK && wantValue != haveValue:
+			diffs = append(diffs, Difference{Field: "env " + k, Declared: wantValue, Actual: haveValue})
+		case !declaredOK:
+			if def, ok := defaults[k]; ok && def == haveValue {
+				continue
+			}
+			diffs = append(diffs, Difference{Field: "env " + k, Declared: "(unset)", Actual: haveValue})
+		}
+	}
+	return diffs
+}
+
+// formatPortMap renders port bindings as a sorted, comparable string
+func formatPortMap(bindings nat.PortMap) string {
+	var parts []string
+	for port, hostBindings := range bindings {
+		for _, b := range hostBindings {
+			host := b.HostPort
+			if b.HostIP != "" {
+				host = b.HostIP + ":" + host
+			}
+			parts = append(parts, fmt.Sprintf("%s->%s", host, port))
+		}
+	}
+	if len(parts) == 0 {
+		return "(none)"
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// Recreate replaces the containers of a service one replica at a time with
+// containers created from the compose files. Each old container is renamed
+// aside and stopped while its replacement starts, since the two may share
+// a name or host ports, and is restored when the replacement fails.
+func Recreate(ctx context.Context, cli *client.Client, p *Project, service string, progress Progress) error {
+	svc, ok := p.Services[service]
+	if !ok {
+		return fmt.Errorf("service %s is not defined", service)
+	}
+	if err := EnsureImage(ctx, cli, svc.Image, progress); err != nil {
+		return fmt.Errorf("service %s: %w", service, err)
+	}
+
+	// Everything the new replicas need is prepared before the old ones go,
+	// so a failure leaves the service running
+	if err := ensureNetworks(ctx, cli, p, progress); err != nil {
+		return err
+	}
+	if err := ensureVolumes(ctx, cli, p, progress); err != nil {
+		return err
+	}
+
+	containers, err := ServiceContainers(ctx, cli, p.Name, service)
+	if err != nil {
+		return err
+	}
+	old := map[int][]container.Summary{}
+	for _, c := range containers {
+		old[ContainerNumber(c)] = append(old[ContainerNumber(c)], c)
+	}
+
+	replicas := svc.Replicas()
+	for number := 1; number <= replicas; number++ {
+		if err := replaceReplica(ctx, cli, p, service, number, old[number], progress); err != nil {
+			return err
+		}
+		delete(old, number)
+	}
+
+	// Containers above the declared count have no replacement
+	for _, extra := range old {
+		for _, c := range extra {
+			progress(fmt.Sprintf("Removing %s", DisplayName(c)))
+			if err := removeContainer(ctx, cli, c.ID); err != nil {
+				return fmt.Errorf("%s: %w", DisplayName(c), err)
+			}
+		}
+	}
+	return nil
+}
+
+// replaceReplica creates a replica of a service in place of the old
+// containers with its number. The old containers are removed once the new
+// one runs, or renamed back and restarted when it fails.
+func replaceReplica(ctx context.Context, cli *client.Client, p *Project, service string, number int, old []container.Summary, progress Progress) error {
+	var aside []container.Summary
+	restore := func(cause error) error {
+		for _, c := range aside {
+			if err := cli.ContainerRename(ctx, c.ID, DisplayName(c)); err != nil {
+				return fmt.Errorf("%w; restoring %s also failed: %v", cause, DisplayName(c), err)
+			}
+			if c.State == "running" {
+				if err := cli.ContainerStart(ctx, c.ID, container.StartOptions{}); err != nil {
+					return fmt.Errorf("%w; restarting %s also failed: %v", cause, DisplayName(c), err)
+				}
+			}
+		}
+		return cause
+	}
+
+	for _, c := range old {
+		progress(fmt.Sprintf("Stopping %s", DisplayName(c)))
+		if err := cli.ContainerRename(ctx, c.ID, fmt.Sprintf("%s_old_%s", DisplayName(c), c.ID[:12])); err != nil {
+			return restore(fmt.Errorf("%s: %w", DisplayName(c), err))
+		}
+		aside = append(aside, c)
+		if err := cli.ContainerStop(ctx, c.ID, container.StopOptions{}); err != nil {
+			return restore(fmt.Errorf("%s: %w", DisplayName(c), err))
+		}
+	}
+
+	id, err := CreateReplica(ctx, cli, p, service, number, progress)
+	if err != nil {
+		if id != "" {
+			_ = cli.ContainerRemove(ctx, id, container.RemoveOptions{Force: true})
+		}
+		return restore(err)
+	}
+
+	for _, c := range aside {
+		progress(fmt.Sprintf("Removing %s", DisplayName(c)))
+		if err := cli.ContainerRemove(ctx, c.ID, container.RemoveOptions{}); err != nil {
+			return fmt.Errorf("%s: %w", DisplayName(c), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/registryauth"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Progress receives a line describing each step of a project operation
+type Progress func(step string)
+
+// ProjectFilter returns the filter matching resources of a project
+func ProjectFilter(project string) filters.Args {
+	return filters.NewArgs(filters.Arg("label", LabelProject+"="+project))
+}
+
+// ServiceFilter returns the filter matching containers of a service
+func ServiceFilter(project, service string) filters.Args {
+	args := ProjectFilter(project)
+	args.Add("label", LabelService+"="+service)
+	return args
+}
+
+// ContainerNumber returns the replica number of a service container
+func ContainerNumber(c container.Summary) int {
+	n, _ := strconv.Atoi(c.Labels[LabelNumber])
+	return n
+}
+
+// ServiceContainers lists the containers of a service ordered by replica
+// number
+func ServiceContainers(ctx context.Context, cli *client.Client, project, service string) ([]container.Summary, error) {
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true, Filters: ServiceFilter(project, service)})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return ContainerNumber(containers[i]) < ContainerNumber(containers[j])
+	})
+	return containers, nil
+}
+
+// projectContainers lists all containers of a project
+func projectContainers(ctx context.Context, cli *client.Client, project string) ([]container.Summary, error) {
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true, Filters: ProjectFilter(project)})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		si, sj := containers[i].Labels[LabelService], containers[j].Labels[LabelService]
+		if si != sj {
+			return si < sj
+		}
+		return ContainerNumber(containers[i]) < ContainerNumber(containers[j])
+	})
+	return containers, nil
+}
+
+// DisplayName returns the name of a container without the leading slash
+func DisplayName(c container.Summary) string {
+	if len(c.Names) > 0 {
+		return strings.TrimPrefix(c.Names[0], "/")
+	}
+	return c.ID[:12]
+}
+
+// Up creates the networks and volumes of a project, then creates, starts
+// or removes service containers until each service runs its declared
+// number of replicas. Existing containers are not recreated.
+func Up(ctx context.Context, cli *client.Client, p *Project, progress Progress) error {
+	if err := ensureNetworks(ctx, cli, p, progress); err != nil {
+		return err
+	}
+	if err := ensureVolumes(ctx, cli, p, progress); err != nil {
+		return err
+	}
+
+	for _, service := range p.ServiceNames() {
+		if err := EnsureImage(ctx, cli, p.Services[service].Image, progress); err != nil {
+			return fmt.Errorf("service %s: %w", service, err)
+		}
+		if err := Scale(ctx, cli, p, service, p.Services[service].Replicas(), progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scale brings a service to the given number of running replicas. Missing
+// replicas are created from the project definition, stopped ones are
+// started and replicas above the count are removed.
+func Scale(ctx context.Context, cli *client.Client, p *Project, service string, replicas int, progress Progress) error {
+	containers, err := ServiceContainers(ctx, cli, p.Name, service)
+	if err != nil {
+		return err
+	}
+
+	existing := map[int]bool{}
+	for _, c := range containers {
+		number := ContainerNumber(c)
+		if number > replicas {
+			progress(fmt.Sprintf("Removing %s", DisplayName(c)))
+			if err := removeContainer(ctx, cli, c.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		existing[number] = true
+		if c.State != "running" {
+			progress(fmt.Sprintf("Starting %s", DisplayName(c)))
+			if err := cli.ContainerStart(ctx, c.ID, container.StartOptions{}); err != nil {
+				return fmt.Errorf("%s: %w", DisplayName(c), err)
+			}
+		}
+	}
+
+	for number := 1; number <= replicas; number++ {
+		if existing[number] {
+			continue
+		}
+		if _, err := CreateReplica(ctx, cli, p, service, number, progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateReplica creates and starts a replica of a service and returns the
+// new container ID
+func CreateReplica(ctx context.Context, cli *client.Client, p *Project, service string, number int, progress Progress) (string, error) {
+	spec, err := p.ContainerSpec(service, number)
+	if err != nil {
+		return "", err
+	}
+
+	progress(fmt.Sprintf("Creating %s", spec.Name))
+	networking := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{spec.Primary: spec.Networks[spec.Primary]},
+	}
+	resp, err := cli.ContainerCreate(ctx, spec.Config, spec.HostConfig, networking, nil, spec.Name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", spec.Name, err)
+	}
+
+	for name, endpoint := range spec.Networks {
+		if name == spec.Primary {
+			continue
+		}
+		if err := cli.NetworkConnect(ctx, name, resp.ID, endpoint); err != nil {
+			return resp.ID, fmt.Errorf("%s: connecting to %s: %w", spec.Name, name, err)
+		}
+	}
+
+	progress(fmt.Sprintf("Starting %s", spec.Name))
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return resp.ID, fmt.Errorf("%s: %w", spec.Name, err)
+	}
+	return resp.ID, nil
+}
+
+// removeContainer stops and removes a container
+func removeContainer(ctx context.Context, cli *client.Client, id string) error {
+	if err := cli.ContainerStop(ctx, id, container.StopOptions{}); err != nil {
+		return err
+	}
+	return cli.ContainerRemove(ctx, id, container.RemoveOptions{})
+}
+
+// usedNetworks returns the compose network keys used by any service
+func usedNetworks(p *Project) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, service := range p.ServiceNames() {
+		for _, key := range p.ServiceNetworks(p.Services[service]) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// ensureNetworks creates the missing networks of a project
+func ensureNetworks(ctx context.Context, cli *client.Client, p *Project, progress Progress) error {
+	for _, key := range usedNetworks(p) {
+		name := p.NetworkName(key)
+		_, err := cli.NetworkInspect(ctx, name, network.InspectOptions{})
+		if err == nil {
+			continue
+		}
+		if !errdefs.IsNotFound(err) {
+			return err
+		}
+
+		def := p.Networks[key]
+		if def.External {
+			return fmt.Errorf("external network %s not found", name)
+		}
+
+		labels := map[string]string{LabelProject: p.Name, LabelNetwork: key}
+		for k, v := range def.Labels {
+			labels[k] = v
+		}
+
+		progress(fmt.Sprintf("Creating network %s", name))
+		_, err = cli.NetworkCreate(ctx, name, network.CreateOptions{
+			Driver:   def.Driver,
+			Internal: def.Internal,
+			Options:  def.Options,
+			Labels:   labels,
+		})
+		if err != nil {
+			return fmt.Errorf("network %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ensureVolumes creates the missing named volumes of a project
+func ensureVolumes(ctx context.Context, cli *client.Client, p *Project, progress Progress) error {
+	keys := make([]string, 0, len(p.Volumes))
+	for key := range p.Volumes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		def := p.Volumes[key]
+		name := p.VolumeName(key)
+		_, err := cli.VolumeInspect(ctx, name)
+		if err == nil {
+			continue
+		}
+		if !errdefs.IsNotFound(err) {
+			return err
+		}
+		if def.External {
+			return fmt.Errorf("external volume %s not found", name)
+		}
+
+		labels := map[string]string{LabelProject: p.Name, LabelVolume: key}
+		for k, v := range def.Labels {
+			labels[k] = v
+		}
+
+		progress(fmt.Sprintf("Creating volume %s", name))
+		_, err = cli.VolumeCreate(ctx, volume.CreateOptions{
+			Name:       name,
+			Driver:     def.Driver,
+			DriverOpts: def.Options,
+			Labels:     labels,
+		})
+		if err != nil {
+			return fmt.Errorf("volume %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// EnsureImage pulls an image unless it is already present
+func EnsureImage(ctx context.Context, cli *client.Client, ref string, progress Progress) error {
+	if ref == "" {
+		return fmt.Errorf("no image; building is not supported")
+	}
+	_, err := cli.ImageInspect(ctx, ref)
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return err
+	}
+	return PullImage(ctx, cli, ref, progress)
+}
+
+// PullImage pulls an image and waits for the pull to finish
+func PullImage(ctx context.Context, cli *client.Client, ref string, progress Progress) error {
+	progress(fmt.Sprintf("Pulling %s", ref))
+	options, err := registryauth.PullOptions(ref)
+	if err != nil {
+		return fmt.Errorf("pulling %s: %w", ref, err)
+	}
+	reader, err := cli.ImagePull(ctx, ref, options)
+	if err != nil {
+		return fmt.Errorf("pulling %s: %w", ref, err)
+	}
+	defer reader.Close()
+
+	// The daemon reports failures such as a missing manifest in the stream
+	if err := jsonmessage.DisplayJSONMessagesStream(reader, io.Discard, 0, false, nil); err != nil {
+		return fmt.Errorf("pulling %s: %w", ref, err)
+	}
+	return nil
+}
+
+// Pull pulls the images of all services that declare one
+func Pull(ctx context.Context, cli *client.Client, p *Project, progress Progress) error {
+	for _, service := range p.ServiceNames() {
+		svc := p.Services[service]
+		if svc.Image == "" {
+			progress(fmt.Sprintf("Skipping %s: no image", service))
+			continue
+		}
+		if err := PullImage(ctx, cli, svc.Image, progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops all running containers of a project
+func Stop(ctx context.Context, cli *client.Client, project string, progress Progress) error {
+	containers, err := projectContainers(ctx, cli, project)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if c.State != "running" {
+			continue
+		}
+		progress(fmt.Sprintf("Stopping %s", DisplayName(c)))
+		if err := cli.ContainerStop(ctx, c.ID, container.StopOptions{}); err != nil {
+			return fmt.Errorf("%s: %w", DisplayName(c), err)
+		}
+	}
+	return nil
+}
+
+// Restart restarts all containers of a project
+func Restart(ctx context.Context, cli *client.Client, project string, progress Progress) error {
+	containers, err := projectContainers(ctx, cli, project)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		progress(fmt.Sprintf("Restarting %s", DisplayName(c)))
+		if err := cli.ContainerRestart(ctx, c.ID, container.StopOptions{}); err != nil {
+			return fmt.Errorf("%s: %w", DisplayName(c), err)
+		}
+	}
+	return nil
+}
+
+// Down stops and removes the containers and networks of a project. Volumes
+// are kept.
+func Down(ctx context.Context, cli *client.Client, project string, progress Progress) error {
+	containers, err := projectContainers(ctx, cli, project)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		progress(fmt.Sprintf("Removing %s", DisplayName(c)))
+		if err := removeContainer(ctx, cli, c.ID); err != nil {
+			return fmt.Errorf("%s: %w", DisplayName(c), err)
+		}
+	}
+
+	networks, err := cli.NetworkList(ctx, network.ListOptions{Filters: ProjectFilter(project)})
+	if err != nil {
+		return err
+	}
+	for _, nw := range networks {
+		progress(fmt.Sprintf("Removing network %s", nw.Name))
+		if err := cli.NetworkRemove(ctx, nw.ID); err != nil {
+			return fmt.Errorf("network %s: %w", nw.Name, err)
+		}
+	}
+	return nil
+}
+
+// Logs returns the last lines of output of every container of a project,
+// each line prefixed with the service and replica number
+func Logs(ctx context.Context, cli *client.Client, project string, tail int) (string, error) {
+	containers, err := projectContainers(ctx, cli, project)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for _, c := range containers {
+		prefix := fmt.Sprintf("%s-%d | ", c.Labels[LabelService], ContainerNumber(c))
+
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return "", err
+		}
+		reader, err := cli.ContainerLogs(ctx, c.ID, container.LogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Tail:       strconv.Itoa(tail),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		var buf bytes.Buffer
+		if info.Config != nil && info.Config.Tty {
+			_, err = io.Copy(&buf, reader)
+		} else {
+			_, err = stdcopy.StdCopy(&buf, &buf, reader)
+		}
+		reader.Close()
+		if err != nil {
+			return "", err
+		}
+
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if line != "" {
+				out.WriteString(prefix + line + "\n")
+			}
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,421 @@
+package compose
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project is a loaded compose project
+type Project struct {
+	Name        string
+	WorkingDir  string
+	ConfigFiles []string
+	File
+}
+
+// Load reads and merges the given compose files. Relative paths in the files
+// are resolved against workingDir, which defaults to the directory of the
+// first file. Variables are interpolated from the environment and the
+// project's .env file.
+func Load(name, workingDir string, configFiles []string) (*Project, error) {
+	if len(configFiles) == 0 {
+		return nil, fmt.Errorf("no compose files given")
+	}
+	// Paths end up in container labels, so make them independent of the cwd
+	absFiles := make([]string, 0, len(configFiles))
+	for _, path := range configFiles {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		absFiles = append(absFiles, abs)
+	}
+	configFiles = absFiles
+
+	if workingDir == "" {
+		workingDir = filepath.Dir(configFiles[0])
+	}
+
+	env, err := loadEnv(filepath.Join(workingDir, ".env"))
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]interface{}{}
+	for _, path := range configFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// Variables are substituted in the decoded values, so comments and
+		// keys are left alone
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if root.Kind == 0 {
+			// An empty file
+			continue
+		}
+		resolveEnvironment(&root, env)
+		if err := interpolateNode(&root, env); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		var doc map[string]interface{}
+		if err := root.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		mergeMaps(merged, doc)
+	}
+
+	// Settings dockerNav does not apply would silently make containers
+	// differ from the file, so files using them are refused
+	if err := checkKeys("", merged, reflect.TypeOf(File{})); err != nil {
+		return nil, err
+	}
+
+	// Round-trip the merged document to decode it with the typed unmarshalers
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for service, svc := range file.Services {
+		if svc.ContainerName != "" && svc.Replicas() > 1 {
+			return nil, fmt.Errorf("service %s: container_name %s cannot be given to %d replicas", service, svc.ContainerName, svc.Replicas())
+		}
+	}
+
+	if name == "" {
+		name = file.Name
+	}
+	if name == "" {
+		name = filepath.Base(workingDir)
+	}
+
+	return &Project{
+		Name:        normalizeProjectName(name),
+		WorkingDir:  workingDir,
+		ConfigFiles: configFiles,
+		File:        file,
+	}, nil
+}
+
+// interpolateNode substitutes variables in the scalar values below node.
+// A plain scalar takes its type from the substituted value, as it would
+// have had the value been written in the file.
+func interpolateNode(node *yaml.Node, env map[string]string) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := interpolateNode(child, env); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolateNode(node.Content[i], env); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return nil
+		}
+		value, err := Interpolate(node.Value, env)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = value
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+	}
+	return nil
+}
+
+// resolveEnvironment fills in the service environment variables given
+// without a value, like FOO in a list or FOO: in a mapping, from env. Those
+// not set in env are left out, as compose does. Values taken from env are
+// escaped so that interpolation keeps them as they are.
+func resolveEnvironment(root *yaml.Node, env map[string]string) {
+	if len(root.Content) == 0 {
+		return
+	}
+	services := mappingValue(root.Content[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return
+	}
+	escape := func(v string) string { return strings.ReplaceAll(v, "$", "$$") }
+
+	for i := 1; i < len(services.Content); i += 2 {
+		environment := mappingValue(services.Content[i], "environment")
+		if environment == nil {
+			continue
+		}
+		var content []*yaml.Node
+		switch environment.Kind {
+		case yaml.SequenceNode:
+			for _, item := range environment.Content {
+				if k, _, ok := strings.Cut(item.Value, "="); !ok && item.Kind == yaml.ScalarNode {
+					value, set := env[k]
+					if !set {
+						continue
+					}
+					item.Value = k + "=" + escape(value)
+				}
+				content = append(content, item)
+			}
+		case yaml.MappingNode:
+			for j := 0; j+1 < len(environment.Content); j += 2 {
+				k, v := environment.Content[j], environment.Content[j+1]
+				if v.Kind == yaml.ScalarNode && v.ShortTag() == "!!null" {
+					value, set := env[k.Value]
+					if !set {
+						continue
+					}
+					v = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: escape(value), Line: v.Line}
+				}
+				content = append(content, k, v)
+			}
+		default:
+			continue
+		}
+		environment.Content = content
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// checkKeys reports the first key of a decoded document that has no field
+// in the type it decodes into. path is the dotted path of the document.
+// Extension keys starting with x- and the obsolete top-level version are
+// accepted.
+func checkKeys(path string, value interface{}, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	doc, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var fieldType reflect.Type
+		switch t.Kind() {
+		case reflect.Map:
+			fieldType = t.Elem()
+		case reflect.Struct:
+			if strings.HasPrefix(k, "x-") || (path == "" && k == "version") {
+				continue
+			}
+			field, ok := yamlField(t, k)
+			if !ok {
+				return fmt.Errorf("%s%s is not supported", path, k)
+			}
+			fieldType = field.Type
+		default:
+			return nil
+		}
+		if err := checkKeys(path+k+".", doc[k], fieldType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yamlField returns the struct field decoded from a yaml key
+func yamlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name, _, _ := strings.Cut(field.Tag.Get("yaml"), ","); name == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// mergeMaps merges src into dst. Nested mappings are merged recursively,
+// any other value in src replaces the one in dst.
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// projectNameInvalid matches characters compose does not allow in names
+var projectNameInvalid = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// normalizeProjectName lowercases a name and strips invalid characters
+func normalizeProjectName(name string) string {
+	return projectNameInvalid.ReplaceAllString(strings.ToLower(name), "")
+}
+
+// loadEnv returns the process environment overlaid on the values of an
+// env file. A missing file is not an error.
+func loadEnv(path string) (map[string]string, error) {
+	env := map[string]string{}
+
+	fileEnv, err := readEnvFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for k, v := range fileEnv {
+		env[k] = v
+	}
+
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	return env, nil
+}
+
+// readEnvFile parses a file of KEY=VALUE lines, skipping comments and
+// blank lines and removing surrounding quotes from values
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		env[strings.TrimSpace(k)] = v
+	}
+	return env, scanner.Err()
+}
+
+// ReadEnvFiles reads the env_file entries of a service relative to the
+// project directory
+func (p *Project) ReadEnvFiles(svc Service) ([]string, error) {
+	var env []string
+	for _, path := range svc.EnvFile {
+		values, err := readEnvFile(p.resolvePath(path))
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, MappingOrList(values).List()...)
+	}
+	return env, nil
+}
+
+// resolvePath makes a path from a compose file absolute
+func (p *Project) resolvePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.WorkingDir, path)
+}
+
+// variablePattern matches $VAR, ${VAR} and ${VAR<op>value} references
+var variablePattern = regexp.MustCompile(`\$(?:\$|\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?[-?+])((?:[^{}]|\{[^}]*\})*))?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// Interpolate substitutes variables in compose file content. It supports
+// $VAR, ${VAR}, ${VAR:-default}, ${VAR-default}, ${VAR:?error},
+// ${VAR?error}, ${VAR:+replacement}, ${VAR+replacement} and $$ escapes.
+func Interpolate(content string, env map[string]string) (string, error) {
+	var firstErr error
+
+	result := variablePattern.ReplaceAllStringFunc(content, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+
+		groups := variablePattern.FindStringSubmatch(match)
+		name, op, arg := groups[1], groups[2], groups[3]
+		if name == "" {
+			name = groups[4]
+		}
+		value, set := env[name]
+
+		switch op {
+		case ":-":
+			if value == "" {
+				return arg
+			}
+		case "-":
+			if !set {
+				return arg
+			}
+		case ":?":
+			if value == "" && firstErr == nil {
+				firstErr = fmt.Errorf("required variable %s is missing a value: %s", name, arg)
+			}
+		case "?":
+			if !set && firstErr == nil {
+				firstErr = fmt.Errorf("required variable %s is missing: %s", name, arg)
+			}
+		case ":+":
+			if value != "" {
+				return arg
+			}
+			return ""
+		case "+":
+			if set {
+				return arg
+			}
+			return ""
+		}
+		return value
+	})
+
+	return result, firstErr
+}
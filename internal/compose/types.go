@@ -0,0 +1,330 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/shellwords"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the subset of the compose file format dockerNav understands
+type File struct {
+	Name     string             `yaml:"name"`
+	Services map[string]Service `yaml:"services"`
+	Networks map[string]Network `yaml:"networks"`
+	Volumes  map[string]Volume  `yaml:"volumes"`
+}
+
+// Service is a service definition of a compose file
+type Service struct {
+	Image         string          `yaml:"image"`
+	Build         interface{}     `yaml:"build"`
+	ContainerName string          `yaml:"container_name"`
+	Command       ShellCommand    `yaml:"command"`
+	Entrypoint    ShellCommand    `yaml:"entrypoint"`
+	Environment   MappingOrList   `yaml:"environment"`
+	EnvFile       StringOrList    `yaml:"env_file"`
+	Ports         []PortConfig    `yaml:"ports"`
+	Expose        []string        `yaml:"expose"`
+	Volumes       []VolumeConfig  `yaml:"volumes"`
+	Networks      ServiceNetworks `yaml:"networks"`
+	Labels        MappingOrList   `yaml:"labels"`
+	Restart       string          `yaml:"restart"`
+	Scale         *int            `yaml:"scale"`
+	Deploy        *Deploy         `yaml:"deploy"`
+	WorkingDir    string          `yaml:"working_dir"`
+	User          string          `yaml:"user"`
+	Hostname      string          `yaml:"hostname"`
+	DependsOn     DependsOn       `yaml:"depends_on"`
+	Privileged    bool            `yaml:"privileged"`
+	ReadOnly      bool            `yaml:"read_only"`
+	CapAdd        []string        `yaml:"cap_add"`
+	CapDrop       []string        `yaml:"cap_drop"`
+	ExtraHosts    StringOrList    `yaml:"extra_hosts"`
+	DNS           StringOrList    `yaml:"dns"`
+	Tmpfs         StringOrList    `yaml:"tmpfs"`
+	StopSignal    string          `yaml:"stop_signal"`
+	Tty           bool            `yaml:"tty"`
+	StdinOpen     bool            `yaml:"stdin_open"`
+}
+
+// Deploy holds the deploy section of a service
+type Deploy struct {
+	Replicas *int `yaml:"replicas"`
+}
+
+// Network is a top-level network definition
+type Network struct {
+	Name     string            `yaml:"name"`
+	Driver   string            `yaml:"driver"`
+	External bool              `yaml:"external"`
+	Internal bool              `yaml:"internal"`
+	Labels   MappingOrList     `yaml:"labels"`
+	Options  map[string]string `yaml:"driver_opts"`
+}
+
+// Volume is a top-level volume definition
+type Volume struct {
+	Name     string            `yaml:"name"`
+	Driver   string            `yaml:"driver"`
+	External bool              `yaml:"external"`
+	Labels   MappingOrList     `yaml:"labels"`
+	Options  map[string]string `yaml:"driver_opts"`
+}
+
+// ServiceNetwork holds per-network settings of a service
+type ServiceNetwork struct {
+	Aliases     []string `yaml:"aliases"`
+	IPv4Address string   `yaml:"ipv4_address"`
+	IPv6Address string   `yaml:"ipv6_address"`
+}
+
+// ServiceNetworks maps network names to their service settings and accepts
+// both the list and the mapping syntax
+type ServiceNetworks map[string]*ServiceNetwork
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (n *ServiceNetworks) UnmarshalYAML(node *yaml.Node) error {
+	result := ServiceNetworks{}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			return err
+		}
+		for _, name := range names {
+			result[name] = nil
+		}
+	case yaml.MappingNode:
+		var networks map[string]*ServiceNetwork
+		if err := node.Decode(&networks); err != nil {
+			return err
+		}
+		for name, settings := range networks {
+			result[name] = settings
+		}
+	default:
+		return fmt.Errorf("line %d: networks must be a list or a mapping", node.Line)
+	}
+	*n = result
+	return nil
+}
+
+// DependsOn lists the services a service depends on, accepting both the
+// list and the mapping syntax
+type DependsOn []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *DependsOn) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			return err
+		}
+		*d = names
+	case yaml.MappingNode:
+		var deps map[string]interface{}
+		if err := node.Decode(&deps); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		*d = names
+	default:
+		return fmt.Errorf("line %d: depends_on must be a list or a mapping", node.Line)
+	}
+	return nil
+}
+
+// MappingOrList holds KEY=VALUE pairs given either as a mapping or as a list
+type MappingOrList map[string]string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (m *MappingOrList) UnmarshalYAML(node *yaml.Node) error {
+	result := MappingOrList{}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			k, v, _ := strings.Cut(item, "=")
+			result[k] = v
+		}
+	case yaml.MappingNode:
+		var items map[string]interface{}
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		for k, v := range items {
+			if v == nil {
+				result[k] = ""
+				continue
+			}
+			result[k] = fmt.Sprint(v)
+		}
+	default:
+		return fmt.Errorf("line %d: expected a list or a mapping", node.Line)
+	}
+	*m = result
+	return nil
+}
+
+// List returns the pairs as sorted KEY=VALUE strings
+func (m MappingOrList) List() []string {
+	list := make([]string, 0, len(m))
+	for k, v := range m {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// StringOrList holds values given either as a single string or as a list
+type StringOrList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (s *StringOrList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*s = []string{node.Value}
+	case yaml.SequenceNode:
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		*s = items
+	case yaml.MappingNode:
+		// extra_hosts may be written as a host: ip mapping
+		var items map[string]string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		list := make([]string, 0, len(items))
+		for k, v := range items {
+			list = append(list, k+":"+v)
+		}
+		sort.Strings(list)
+		*s = list
+	default:
+		return fmt.Errorf("line %d: expected a string or a list", node.Line)
+	}
+	return nil
+}
+
+// ShellCommand holds a command given either as a string or as a list. The
+// string form is split like a shell would.
+type ShellCommand []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (c *ShellCommand) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		args, err := shellwords.Split(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		*c = args
+	case yaml.SequenceNode:
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		*c = items
+	default:
+		return fmt.Errorf("line %d: command must be a string or a list", node.Line)
+	}
+	return nil
+}
+
+// PortConfig is a port mapping in docker run -p syntax
+type PortConfig string
+
+// UnmarshalYAML implements yaml.Unmarshaler, accepting the short and the
+// long port syntax
+func (p *PortConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = PortConfig(node.Value)
+		return nil
+	}
+
+	var long struct {
+		Target    int    `yaml:"target"`
+		Published string `yaml:"published"`
+		HostIP    string `yaml:"host_ip"`
+		Protocol  string `yaml:"protocol"`
+	}
+	if err := node.Decode(&long); err != nil {
+		return err
+	}
+	if long.Target == 0 {
+		return fmt.Errorf("line %d: port target is required", node.Line)
+	}
+
+	spec := strconv.Itoa(long.Target)
+	if long.Published != "" {
+		spec = long.Published + ":" + spec
+		if long.HostIP != "" {
+			spec = long.HostIP + ":" + spec
+		}
+	}
+	if long.Protocol != "" {
+		spec += "/" + long.Protocol
+	}
+	*p = PortConfig(spec)
+	return nil
+}
+
+// VolumeConfig is a service volume in source:target[:mode] syntax
+type VolumeConfig string
+
+// UnmarshalYAML implements yaml.Unmarshaler, accepting the short and the
+// long volume syntax
+func (v *VolumeConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = VolumeConfig(node.Value)
+		return nil
+	}
+
+	var long struct {
+		Type     string `yaml:"type"`
+		Source   string `yaml:"source"`
+		Target   string `yaml:"target"`
+		ReadOnly bool   `yaml:"read_only"`
+	}
+	if err := node.Decode(&long); err != nil {
+		return err
+	}
+	if long.Target == "" {
+		return fmt.Errorf("line %d: volume target is required", node.Line)
+	}
+
+	spec := long.Target
+	if long.Source != "" {
+		spec = long.Source + ":" + spec
+	}
+	if long.ReadOnly {
+		spec += ":ro"
+	}
+	*v = VolumeConfig(spec)
+	return nil
+}
+
+// Replicas returns the number of containers declared for the service
+func (s Service) Replicas() int {
+	if s.Deploy != nil && s.Deploy.Replicas != nil {
+		return *s.Deploy.Replicas
+	}
+	if s.Scale != nil {
+		return *s.Scale
+	}
+	return 1
+}
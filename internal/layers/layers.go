@@ -0,0 +1,394 @@
+// Package layers reads an image archive as written by docker save and works
+// out which files every layer adds, modifies or deletes.
+package layers
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxBlobInMemory is the size up to which archive entries are kept in
+// memory, so that the manifest and image config can be read after the
+// layers, which may come first in the archive
+const maxBlobInMemory = 4 << 20
+
+// Whiteout markers of the layer format
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// ChangeKind is what a layer did to a path
+type ChangeKind int
+
+// Kinds of changes
+const (
+	Added ChangeKind = iota
+	Modified
+	Deleted
+)
+
+// String returns the name of the change kind
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	}
+	return "deleted"
+}
+
+// Change is a path added, modified or deleted by a layer. The size of a
+// deletion is what the removed path took in the lower layers.
+type Change struct {
+	Path string
+	Kind ChangeKind
+	Size int64
+	Dir  bool
+}
+
+// Layer is a layer of an image with its changes
+type Layer struct {
+	Digest  string
+	Command string
+	Size    int64 // size of the layer archive
+	Changes []Change
+	Wasted  int64 // bytes of lower layers this layer overwrites or deletes
+}
+
+// Count returns the number of changes of a kind
+func (l *Layer) Count(kind ChangeKind) int {
+	n := 0
+	for _, c := range l.Changes {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// WastedFile is a path whose content in lower layers is hidden by later ones
+type WastedFile struct {
+	Path   string
+	Size   int64
+	Copies int
+}
+
+// Image is the analysed content of an image
+type Image struct {
+	Layers      []*Layer
+	FileSize    int64 // bytes of all files in all layers
+	Wasted      int64
+	WastedFiles []WastedFile // largest first
+}
+
+// Efficiency returns the share of the file bytes that are visible in the
+// final filesystem
+func (img *Image) Efficiency() float64 {
+	if img.FileSize == 0 {
+		return 1
+	}
+	return float64(img.FileSize-img.Wasted) / float64(img.FileSize)
+}
+
+// entry is a file of a layer archive
+type entry struct {
+	path string
+	size int64
+	dir  bool
+}
+
+// manifest is an entry of manifest.json in a docker save archive
+type manifest struct {
+	Config string
+	Layers []string
+}
+
+// config holds the history of an image config
+type config struct {
+	History []struct {
+		CreatedBy  string `json:"created_by"`
+		EmptyLayer bool   `json:"empty_layer"`
+	} `json:"history"`
+}
+
+// Read reads a docker save archive of a single image. progress, if not nil,
+// is called with the number of bytes read so far.
+func Read(r io.Reader, progress func(read int64)) (*Image, error) {
+	counter := NewCountingReader(r, progress)
+	archive := tar.NewReader(counter)
+
+	blobs := map[string][]byte{}
+	files := map[string][]entry{}
+	sizes := map[string]int64{}
+	links := map[string]string{}
+
+	for {
+		hdr, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(hdr.Name)
+
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			// Older archives link identical layers to a single copy
+			links[name] = path.Join(path.Dir(name), hdr.Linkname)
+			continue
+		case tar.TypeReg:
+		default:
+			continue
+		}
+
+		sizes[name] = hdr.Size
+		var content io.Reader = archive
+		if hdr.Size <= maxBlobInMemory {
+			data, err := io.ReadAll(archive)
+			if err != nil {
+				return nil, err
+			}
+			blobs[name] = data
+			content = bytes.NewReader(data)
+		}
+		// Entries that are not layer archives, such as configs, fail to parse.
+		// Only those are needed afterwards.
+		if entries, err := readLayer(content); err == nil {
+			files[name] = entries
+			delete(blobs, name)
+		} else if _, err := io.Copy(io.Discard, archive); err != nil {
+			return nil, err
+		}
+	}
+
+	data, ok := blobs["manifest.json"]
+	if !ok {
+		return nil, fmt.Errorf("manifest.json not found; not an image archive")
+	}
+	var manifests []manifest
+	if err := json.Unmarshal(data, &manifests); err != nil {
+		return nil, fmt.Errorf("manifest.json: %w", err)
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("manifest.json lists no images")
+	}
+	m := manifests[0]
+
+	// The commands of the history entries that created a layer
+	var commands []string
+	var cfg config
+	if err := json.Unmarshal(blobs[m.Config], &cfg); err == nil {
+		for _, h := range cfg.History {
+			if !h.EmptyLayer {
+				commands = append(commands, h.CreatedBy)
+			}
+		}
+	}
+
+	img := &Image{}
+	var layerFiles [][]entry
+	for i, name := range m.Layers {
+		name = path.Clean(name)
+		if target, ok := links[name]; ok {
+			name = target
+		}
+		entries, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("layer %s not found in the archive", name)
+		}
+		layer := &Layer{Digest: layerDigest(name), Size: sizes[name]}
+		if i < len(commands) {
+			layer.Command = commands[i]
+		}
+		img.Layers = append(img.Layers, layer)
+		layerFiles = append(layerFiles, entries)
+	}
+	img.analyse(layerFiles)
+	return img, nil
+}
+
+// layerDigest derives the digest of a layer from its path in the archive
+func layerDigest(name string) string {
+	if strings.HasPrefix(name, "blobs/") {
+		parts := strings.Split(name, "/")
+		return parts[1] + ":" + parts[len(parts)-1]
+	}
+	// Older archives store layers as <id>/layer.tar
+	return path.Dir(name)
+}
+
+// readLayer lists the entries of a layer archive, which may be compressed
+func readLayer(r io.Reader) ([]entry, error) {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	layer := tar.NewReader(r)
+	entries := []entry{}
+	for {
+		hdr, err := layer.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		p := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if p == "" {
+			continue
+		}
+		e := entry{path: p, dir: hdr.Typeflag == tar.TypeDir}
+		if hdr.Typeflag == tar.TypeReg {
+			e.size = hdr.Size
+		}
+		entries = append(entries, e)
+	}
+}
+
+// analyse replays the layers in order, recording the changes of each layer
+// and the bytes hidden by later layers
+func (img *Image) analyse(layerFiles [][]entry) {
+	filesystem := map[string]entry{}
+	wasted := map[string]*WastedFile{}
+	waste := func(layer *Layer, p string, size int64) {
+		layer.Wasted += size
+		img.Wasted += size
+		w, ok := wasted[p]
+		if !ok {
+			w = &WastedFile{Path: p}
+			wasted[p] = w
+		}
+		w.Size += size
+		w.Copies++
+	}
+
+	// remove deletes a path and everything below it, returning their size
+	remove := func(layer *Layer, p string) (int64, bool) {
+		var size int64
+		dir := false
+		for q, e := range filesystem {
+			if q != p && !strings.HasPrefix(q, p+"/") {
+				continue
+			}
+			if q == p {
+				dir = dir || e.dir
+			} else {
+				dir = true
+			}
+			if !e.dir && e.size > 0 {
+				size += e.size
+				waste(layer, q, e.size)
+			}
+			delete(filesystem, q)
+		}
+		return size, dir
+	}
+
+	for i, entries := range layerFiles {
+		layer := img.Layers[i]
+
+		// Whiteouts apply to the lower layers, so handle them first
+		for _, e := range entries {
+			dir, base := path.Split(e.path)
+			switch {
+			case base == whiteoutOpaque:
+				// Hide the lower content of the directory but keep it
+				target := strings.TrimSuffix(dir, "/")
+				children := map[string]bool{}
+				for q := range filesystem {
+					if rest, ok := strings.CutPrefix(q, target+"/"); ok {
+						first, _, _ := strings.Cut(rest, "/")
+						children[target+"/"+first] = true
+					}
+				}
+				for child := range children {
+					size, isDir := remove(layer, child)
+					layer.Changes = append(layer.Changes, Change{Path: child, Kind: Deleted, Size: size, Dir: isDir})
+				}
+			case strings.HasPrefix(base, whiteoutPrefix):
+				target := dir + strings.TrimPrefix(base, whiteoutPrefix)
+				size, isDir := remove(layer, target)
+				layer.Changes = append(layer.Changes, Change{Path: target, Kind: Deleted, Size: size, Dir: isDir})
+			}
+		}
+
+		for _, e := range entries {
+			if strings.HasPrefix(path.Base(e.path), whiteoutPrefix) {
+				continue
+			}
+			img.FileSize += e.size
+			prev, exists := filesystem[e.path]
+			filesystem[e.path] = e
+			if e.dir {
+				// Directories are only listed through the files they contain
+				continue
+			}
+
+			kind := Added
+			if exists && !prev.dir {
+				kind = Modified
+				if prev.size > 0 {
+					waste(layer, e.path, prev.size)
+				}
+			}
+			layer.Changes = append(layer.Changes, Change{Path: e.path, Kind: kind, Size: e.size})
+		}
+
+		sort.Slice(layer.Changes, func(a, b int) bool {
+			return layer.Changes[a].Path < layer.Changes[b].Path
+		})
+	}
+
+	for _, w := range wasted {
+		img.WastedFiles = append(img.WastedFiles, *w)
+	}
+	sort.Slice(img.WastedFiles, func(a, b int) bool {
+		if img.WastedFiles[a].Size != img.WastedFiles[b].Size {
+			return img.WastedFiles[a].Size > img.WastedFiles[b].Size
+		}
+		return img.WastedFiles[a].Path < img.WastedFiles[b].Path
+	})
+}
+
+// CountingReader reports the number of bytes read from an archive
+type CountingReader struct {
+	r        io.Reader
+	read     int64
+	progress func(int64)
+}
+
+// NewCountingReader returns a reader calling progress, if not nil, with the
+// number of bytes read so far
+func NewCountingReader(r io.Reader, progress func(read int64)) *CountingReader {
+	return &CountingReader{r: r, progress: progress}
+}
+
+// Read implements io.Reader
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	if c.progress != nil && n > 0 {
+		c.progress(c.read)
+	}
+	return n, err
+}
@@ -0,0 +1,398 @@
+// Package registryauth reads and stores registry credentials in the Docker
+// CLI configuration, so that logins are shared with the docker command.
+package registryauth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// DockerHubServer is the address Docker Hub credentials are stored under
+const DockerHubServer = "https://index.docker.io/v1/"
+
+// tokenUsername is the username credential helpers report for identity tokens
+const tokenUsername = "<token>"
+
+// authEntry is a credential stored directly in the config file
+type authEntry struct {
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// configFile holds the credential settings of the Docker CLI config
+type configFile struct {
+	Auths       map[string]authEntry `json:"auths"`
+	CredsStore  string               `json:"credsStore"`
+	CredHelpers map[string]string    `json:"credHelpers"`
+}
+
+// helperCredentials is the payload exchanged with credential helpers
+type helperCredentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// ConfigPath returns the path of the Docker CLI config file, honouring
+// DOCKER_CONFIG like the docker command
+func ConfigPath() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// NormalizeServer returns the address credentials for a registry are
+// stored under: DockerHubServer for Docker Hub and the host otherwise
+func NormalizeServer(server string) string {
+	host := server
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case "", "docker.io", "index.docker.io", "registry-1.docker.io":
+		return DockerHubServer
+	}
+	return host
+}
+
+// ServerForImage returns the registry address of an image reference
+func ServerForImage(ref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", err
+	}
+	return NormalizeServer(reference.Domain(named)), nil
+}
+
+// load reads the config file; a missing file has no credentials
+func load() (*configFile, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &configFile{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// helper returns the credential helper configured for a server, if any
+func (c *configFile) helper(server string) string {
+	if helper, ok := c.CredHelpers[server]; ok {
+		return helper
+	}
+	// Docker Hub may be listed under its host rather than the v1 address
+	if server == DockerHubServer {
+		if helper, ok := c.CredHelpers["docker.io"]; ok {
+			return helper
+		}
+	}
+	return c.CredsStore
+}
+
+// Lookup returns the stored credentials for a registry. The returned config
+// is empty when none are stored.
+func Lookup(server string) (registry.AuthConfig, error) {
+	server = NormalizeServer(server)
+	cfg, err := load()
+	if err != nil {
+		return registry.AuthConfig{}, err
+	}
+
+	if helper := cfg.helper(server); helper != "" {
+		creds, err := helperGet(helper, server)
+		if err != nil || creds != nil {
+			return authFromHelper(server, creds), err
+		}
+	}
+
+	for key, entry := range cfg.Auths {
+		if NormalizeServer(key) != server {
+			continue
+		}
+		auth := registry.AuthConfig{ServerAddress: server, IdentityToken: entry.IdentityToken}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return registry.AuthConfig{}, fmt.Errorf("credentials for %s: %w", key, err)
+			}
+			auth.Username, auth.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		return auth, nil
+	}
+	return registry.AuthConfig{ServerAddress: server}, nil
+}
+
+// authFromHelper converts credentials returned by a helper
+func authFromHelper(server string, creds *helperCredentials) registry.AuthConfig {
+	auth := registry.AuthConfig{ServerAddress: server}
+	if creds == nil {
+		return auth
+	}
+	if creds.Username == tokenUsername {
+		auth.IdentityToken = creds.Secret
+	} else {
+		auth.Username = creds.Username
+		auth.Password = creds.Secret
+	}
+	return auth
+}
+
+// ForImage returns the encoded RegistryAuth for pulling or pushing an
+// image, or "" when no credentials are stored for its registry
+func ForImage(ref string) (string, error) {
+	server, err := ServerForImage(ref)
+	if err != nil {
+		return "", err
+	}
+	auth, err := Lookup(server)
+	if err != nil {
+		return "", fmt.Errorf("credentials for %s: %w", server, err)
+	}
+	if auth.Username == "" && auth.IdentityToken == "" {
+		return "", nil
+	}
+	return registry.EncodeAuthConfig(auth)
+}
+
+// PullOptions returns the pull options for an image with the stored
+// credentials of its registry attached
+func PullOptions(ref string) (image.PullOptions, error) {
+	auth, err := ForImage(ref)
+	if err != nil {
+		return image.PullOptions{}, err
+	}
+	return image.PullOptions{RegistryAuth: auth}, nil
+}
+
+// PushOptions returns the push options for an image with the stored
+// credentials of its registry attached. The daemon expects credentials on
+// every push, so an empty set is sent when none are stored.
+func PushOptions(ref string) (image.PushOptions, error) {
+	auth, err := ForImage(ref)
+	if err != nil {
+		return image.PushOptions{}, err
+	}
+	if auth == "" {
+		if auth, err = registry.EncodeAuthConfig(registry.AuthConfig{}); err != nil {
+			return image.PushOptions{}, err
+		}
+	}
+	return image.PushOptions{RegistryAuth: auth}, nil
+}
+
+// All returns the stored credentials of every registry, keyed by server
+// address, as image builds take them to pull base images
+func All() (map[string]registry.AuthConfig, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	servers := map[string]bool{}
+	for key := range cfg.Auths {
+		servers[NormalizeServer(key)] = true
+	}
+	for key := range cfg.CredHelpers {
+		servers[NormalizeServer(key)] = true
+	}
+	if cfg.CredsStore != "" {
+		listed, err := helperList(cfg.CredsStore)
+		if err != nil {
+			return nil, err
+		}
+		for key := range listed {
+			servers[NormalizeServer(key)] = true
+		}
+	}
+
+	auths := map[string]registry.AuthConfig{}
+	for server := range servers {
+		auth, err := Lookup(server)
+		if err != nil {
+			return nil, fmt.Errorf("credentials for %s: %w", server, err)
+		}
+		if auth.Username != "" || auth.IdentityToken != "" {
+			auths[server] = auth
+		}
+	}
+	return auths, nil
+}
+
+// Save stores credentials for auth.ServerAddress, in the credential helper
+// when one is configured and in the config file otherwise
+func Save(auth registry.AuthConfig) error {
+	server := NormalizeServer(auth.ServerAddress)
+	cfg, err := load()
+	if err != nil {
+		return err
+	}
+
+	if helper := cfg.helper(server); helper != "" {
+		creds := helperCredentials{ServerURL: server, Username: auth.Username, Secret: auth.Password}
+		if auth.IdentityToken != "" {
+			creds.Username = tokenUsername
+			creds.Secret = auth.IdentityToken
+		}
+		return helperStore(helper, creds)
+	}
+
+	entry := authEntry{
+		Auth:          base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password)),
+		IdentityToken: auth.IdentityToken,
+	}
+	return saveEntry(server, entry)
+}
+
+// saveEntry writes a credential into the auths of the config file, keeping
+// every other setting of the file as it is
+func saveEntry(server string, entry authEntry) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	raw := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	auths := map[string]json.RawMessage{}
+	if existing, ok := raw["auths"]; ok {
+		if err := json.Unmarshal(existing, &auths); err != nil {
+			return fmt.Errorf("%s: auths: %w", path, err)
+		}
+	}
+	// Drop entries stored under another form of the address, e.g. with a scheme
+	for key := range auths {
+		if NormalizeServer(key) == server {
+			delete(auths, key)
+		}
+	}
+	if auths[server], err = json.Marshal(entry); err != nil {
+		return err
+	}
+	if raw["auths"], err = json.Marshal(auths); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(raw, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// Replace the file in one step so a failed write cannot truncate it
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// helperGet asks a credential helper for the credentials of a server. It
+// returns nil when the helper has none.
+func helperGet(helper, server string) (*helperCredentials, error) {
+	out, err := runHelper(helper, "get", strings.NewReader(server))
+	if err != nil {
+		if strings.Contains(err.Error(), "credentials not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	creds := &helperCredentials{}
+	if err := json.Unmarshal(out, creds); err != nil {
+		return nil, fmt.Errorf("docker-credential-%s: %w", helper, err)
+	}
+	return creds, nil
+}
+
+// helperList asks a credential helper for the servers it holds
+// credentials for, mapped to their usernames
+func helperList(helper string) (map[string]string, error) {
+	out, err := runHelper(helper, "list", strings.NewReader(""))
+	if err != nil {
+		return nil, err
+	}
+
+	servers := map[string]string{}
+	if err := json.Unmarshal(out, &servers); err != nil {
+		return nil, fmt.Errorf("docker-credential-%s: %w", helper, err)
+	}
+	return servers, nil
+}
+
+// helperStore hands credentials to a credential helper
+func helperStore(helper string, creds helperCredentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	_, err = runHelper(helper, "store", bytes.NewReader(data))
+	return err
+}
+
+// runHelper runs docker-credential-<helper> with an action and input
+func runHelper(helper, action string, input io.Reader) ([]byte, error) {
+	cmd := exec.Command("docker-credential-"+helper, action)
+	cmd.Stdin = input
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		var exitErr *exec.ExitError
+		if msg == "" && errors.As(err, &exitErr) {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("docker-credential-%s %s: %s", helper, action, msg)
+	}
+	return out, nil
+}
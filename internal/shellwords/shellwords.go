@@ -0,0 +1,99 @@
+package shellwords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split splits a command line into words the way a POSIX shell does,
+// honouring single quotes, double quotes and backslash escapes. Variables
+// and globs are not expanded.
+func Split(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune // The quote character currently open, if any
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes a few characters
+			if quote == '"' && !strings.ContainsRune("\"\\$`\n", r) {
+				word.WriteRune('\\')
+			}
+			escaped = false
+			// A backslash before a newline continues the line
+			if r == '\n' || r == '\r' {
+				continue
+			}
+			word.WriteRune(r)
+			inWord = true
+
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+
+		case r == '\\':
+			escaped = true
+
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Quote returns word quoted so that Split returns it unchanged
+func Quote(word string) string {
+	if word == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(word, " \t\n\r'\"\\$`") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// Join quotes words as needed and joins them with spaces
+func Join(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = Quote(word)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,324 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/compose"
+	"github.com/Gostatsog/dockerNav/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// dirName is the directory under the configuration directory holding templates
+const dirName = "templates"
+
+// Template holds the values of a container create form. Every string field
+// may reference variables as ${NAME}, ${NAME:-default} or $NAME.
+type Template struct {
+	Name          string               `yaml:"name"`
+	Description   string               `yaml:"description,omitempty"`
+	Image         string               `yaml:"image"`
+	ContainerName string               `yaml:"container_name,omitempty"`
+	Ports         string               `yaml:"ports,omitempty"`
+	Volumes       string               `yaml:"volumes,omitempty"`
+	Environment   string               `yaml:"environment,omitempty"`
+	Command       string               `yaml:"command,omitempty"`
+	Network       string               `yaml:"network,omitempty"` // space-separated for several networks
+	Endpoints     map[string]*Endpoint `yaml:"endpoints,omitempty"`
+	Restart       string               `yaml:"restart,omitempty"`
+	Advanced      *Advanced            `yaml:"advanced,omitempty"`
+}
+
+// Endpoint holds the settings of one network the container is attached to
+type Endpoint struct {
+	Aliases string `yaml:"aliases,omitempty"`
+	IPv4    string `yaml:"ipv4,omitempty"`
+	IPv6    string `yaml:"ipv6,omitempty"`
+	Links   string `yaml:"links,omitempty"`
+}
+
+// Advanced holds the advanced create form options
+type Advanced struct {
+	Entrypoint        string `yaml:"entrypoint,omitempty"`
+	WorkingDir        string `yaml:"working_dir,omitempty"`
+	User              string `yaml:"user,omitempty"`
+	Hostname          string `yaml:"hostname,omitempty"`
+	Labels            string `yaml:"labels,omitempty"`
+	Memory            string `yaml:"memory,omitempty"`
+	CPUs              string `yaml:"cpus,omitempty"`
+	CapAdd            string `yaml:"cap_add,omitempty"`
+	CapDrop           string `yaml:"cap_drop,omitempty"`
+	Privileged        bool   `yaml:"privileged,omitempty"`
+	ReadOnly          bool   `yaml:"read_only,omitempty"`
+	Tmpfs             string `yaml:"tmpfs,omitempty"`
+	Devices           string `yaml:"devices,omitempty"`
+	ExtraHosts        string `yaml:"extra_hosts,omitempty"`
+	DNS               string `yaml:"dns,omitempty"`
+	LogDriver         string `yaml:"log_driver,omitempty"`
+	LogOpts           string `yaml:"log_opts,omitempty"`
+	HealthCmd         string `yaml:"health_cmd,omitempty"`
+	HealthInterval    string `yaml:"health_interval,omitempty"`
+	HealthTimeout     string `yaml:"health_timeout,omitempty"`
+	HealthRetries     string `yaml:"health_retries,omitempty"`
+	HealthStartPeriod string `yaml:"health_start_period,omitempty"`
+	StopSignal        string `yaml:"stop_signal,omitempty"`
+	StopTimeout       string `yaml:"stop_timeout,omitempty"`
+	AutoRemove        bool   `yaml:"auto_remove,omitempty"`
+}
+
+// Variable is a variable referenced by a template
+type Variable struct {
+	Name    string
+	Default string
+}
+
+// namePattern matches valid template names, which are also file names
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// variableRef matches $NAME and ${NAME} references, the latter with an
+// optional :- or - default
+var variableRef = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)(?::?-([^}]*))?|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// Dir returns the template directory, creating it if needed
+func Dir() (string, error) {
+	path, err := config.Path(dirName)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// path returns the file a template is stored in
+func path(name string) (string, error) {
+	if !namePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid template name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".yaml"), nil
+}
+
+// Parse decodes and validates a template
+func Parse(data []byte) (*Template, error) {
+	var t Template
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	if !namePattern.MatchString(t.Name) {
+		return nil, fmt.Errorf("invalid template name %q: use letters, digits, '.', '_' and '-'", t.Name)
+	}
+	if t.Image == "" {
+		return nil, fmt.Errorf("template %s has no image", t.Name)
+	}
+	return &t, nil
+}
+
+// Marshal encodes a template as YAML
+func Marshal(t *Template) ([]byte, error) {
+	return yaml.Marshal(t)
+}
+
+// List returns the saved templates sorted by name. Files that cannot be
+// parsed are skipped and reported in the returned error.
+func List() ([]Template, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Template
+	var errs []error
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t, err := Parse(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(file), err))
+			continue
+		}
+		list = append(list, *t)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, errors.Join(errs...)
+}
+
+// Read returns the raw YAML of a saved template
+func Read(name string) ([]byte, error) {
+	file, err := path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(file)
+}
+
+// Exists reports whether a template with the given name is saved
+func Exists(name string) bool {
+	file, err := path(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(file)
+	return err == nil
+}
+
+// Save writes a template, replacing any template with the same name
+func Save(t *Template) error {
+	file, err := path(t.Name)
+	if err != nil {
+		return err
+	}
+	data, err := Marshal(t)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// Write parses raw YAML and saves it. When the name changed from oldName
+// the old file is removed, so editing a template can rename it.
+func Write(oldName string, data []byte) (*Template, error) {
+	t, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	file, err := path(t.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return nil, err
+	}
+	if oldName != "" && oldName != t.Name {
+		if err := Delete(oldName); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Delete removes a saved template. A missing template is not an error.
+func Delete(name string) error {
+	file, err := path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// fields returns pointers to every string field of the template except
+// its name and description
+func (t *Template) fields() []*string {
+	fields := []*string{
+		&t.Image, &t.ContainerName, &t.Ports, &t.Volumes, &t.Environment,
+		&t.Command, &t.Network, &t.Restart,
+	}
+	names := make([]string, 0, len(t.Endpoints))
+	for name := range t.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ep := t.Endpoints[name]; ep != nil {
+			fields = append(fields, &ep.Aliases, &ep.IPv4, &ep.IPv6, &ep.Links)
+		}
+	}
+	if a := t.Advanced; a != nil {
+		fields = append(fields,
+			&a.Entrypoint, &a.WorkingDir, &a.User, &a.Hostname, &a.Labels,
+			&a.Memory, &a.CPUs, &a.CapAdd, &a.CapDrop, &a.Tmpfs, &a.Devices,
+			&a.ExtraHosts, &a.DNS, &a.LogDriver, &a.LogOpts, &a.HealthCmd,
+			&a.HealthInterval, &a.HealthTimeout, &a.HealthRetries,
+			&a.HealthStartPeriod, &a.StopSignal, &a.StopTimeout,
+		)
+	}
+	return fields
+}
+
+// Escape doubles every $ in the template's fields, so literal values such
+// as those taken from a form are not read as variables
+func (t *Template) Escape() {
+	for _, field := range t.fields() {
+		*field = strings.ReplaceAll(*field, "$", "$$")
+	}
+}
+
+// Variables returns the $NAME and ${NAME} variables the template
+// references, in order of first use. $$ escapes are not variables.
+func (t *Template) Variables() []Variable {
+	var vars []Variable
+	seen := map[string]bool{}
+	for _, field := range t.fields() {
+		value := strings.ReplaceAll(*field, "$$", "")
+		for _, match := range variableRef.FindAllStringSubmatch(value, -1) {
+			name := match[1]
+			if name == "" {
+				name = match[3]
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			vars = append(vars, Variable{Name: name, Default: match[2]})
+		}
+	}
+	return vars
+}
+
+// Substitute returns a copy of the template with variables replaced by the
+// given values, falling back to the environment and then to the defaults
+// in the template
+func (t *Template) Substitute(values map[string]string) (*Template, error) {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	for k, v := range values {
+		env[k] = v
+	}
+
+	result := *t
+	if t.Endpoints != nil {
+		result.Endpoints = make(map[string]*Endpoint, len(t.Endpoints))
+		for name, ep := range t.Endpoints {
+			if ep != nil {
+				copied := *ep
+				result.Endpoints[name] = &copied
+			}
+		}
+	}
+	if t.Advanced != nil {
+		advanced := *t.Advanced
+		result.Advanced = &advanced
+	}
+	for _, field := range result.fields() {
+		value, err := compose.Interpolate(*field, env)
+		if err != nil {
+			return nil, err
+		}
+		*field = value
+	}
+	return &result, nil
+}
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bulkConcurrency limits how many containers a bulk action touches at once
+const bulkConcurrency = 4
+
+// ContainerBulkResult holds the outcome of a bulk action for one container
+type ContainerBulkResult struct {
+	Name        string
+	ContainerID string
+	Action      string
+	Error       error
+}
+
+// ContainerBulkMsg carries the results of a bulk action
+type ContainerBulkMsg struct {
+	Action  string
+	Results []ContainerBulkResult
+}
+
+// containerName returns the display name of a container
+func containerName(c Summary) string {
+	if len(c.Names) == 0 {
+		return c.ID[:12]
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// resolveAction maps the pause toggle onto pause or unpause for a container
+func resolveAction(action string, c Summary) string {
+	if action == "pause" && c.State == "paused" {
+		return "unpause"
+	}
+	return action
+}
+
+// toggleMark marks or unmarks the selected container
+func (m *ContainerModel) toggleMark() tea.Cmd {
+	item, ok := m.selectedItem()
+	if !ok {
+		return nil
+	}
+	if m.marked[item.container.ID] {
+		delete(m.marked, item.container.ID)
+	} else {
+		m.marked[item.container.ID] = true
+	}
+	cmd := m.refreshItems()
+	m.moveCursorDown()
+	return cmd
+}
+
+// markVisible marks every container matching the current filter
+func (m *ContainerModel) markVisible() tea.Cmd {
+	for _, c := range m.visibleContainers() {
+		m.marked[c.ID] = true
+	}
+	return m.refreshItems()
+}
+
+// invertMarks inverts the marks of the containers matching the current filter
+func (m *ContainerModel) invertMarks() tea.Cmd {
+	for _, c := range m.visibleContainers() {
+		if m.marked[c.ID] {
+			delete(m.marked, c.ID)
+		} else {
+			m.marked[c.ID] = true
+		}
+	}
+	return m.refreshItems()
+}
+
+// markedContainers returns the marked containers in list order
+func (m *ContainerModel) markedContainers() []Summary {
+	var marked []Summary
+	for _, c := range m.containers {
+		if m.marked[c.ID] {
+			marked = append(marked, c)
+		}
+	}
+	return marked
+}
+
+// confirmBulk prepares the confirmation of an action on several containers
+func (m *ContainerModel) confirmBulk(action string, targets []Summary) {
+	m.bulkTargets = targets
+	// A container selected earlier must not receive the confirmed action
+	m.selectedContainer = nil
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Are you sure you want to %s %d containers?\n", action, len(m.bulkTargets))
+	for _, c := range m.bulkTargets {
+		fmt.Fprintf(&b, "\n  • %s (%s)", containerName(c), c.State)
+	}
+
+	m.confirmMsg = b.String()
+	m.confirmAction = action
+	m.state = "confirm"
+}
+
+// performBulkAction returns a command that runs an action on several
+// containers with bounded concurrency
+func (m *ContainerModel) performBulkAction(action string, targets []Summary) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		results := make([]ContainerBulkResult, len(targets))
+		sem := make(chan struct{}, bulkConcurrency)
+		var wg sync.WaitGroup
+
+		for i, c := range targets {
+			wg.Add(1)
+			go func(i int, c Summary) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				resolved := resolveAction(action, c)
+				results[i] = ContainerBulkResult{
+					Name:        containerName(c),
+					ContainerID: c.ID,
+					Action:      resolved,
+					Error:       m.containerAction(ctx, resolved, c.ID),
+				}
+			}(i, c)
+		}
+		wg.Wait()
+
+		return ContainerBulkMsg{
+			Action:  action,
+			Results: results,
+		}
+	}
+}
+
+// renderBulkResults renders the per-container outcome of the last bulk action
+func (m *ContainerModel) renderBulkResults() string {
+	failed := 0
+	rows := make([]string, 0, len(m.bulkResults))
+	for _, r := range m.bulkResults {
+		if r.Error != nil {
+			failed++
+			rows = append(rows, StyleError.Render(fmt.Sprintf("✗ %s: %s failed: %v", r.Name, r.Action, r.Error)))
+		} else {
+			rows = append(rows, StyleSuccess.Render(fmt.Sprintf("✓ %s: %s", r.Name, r.Action)))
+		}
+	}
+
+	summary := fmt.Sprintf("%d succeeded, %d failed", len(m.bulkResults)-failed, failed)
+	return StyleInfoBox.Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			summary,
+			"",
+			strings.Join(rows, "\n"),
+		),
+	)
+}
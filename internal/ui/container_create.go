@@ -3,16 +3,18 @@ package ui
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/Gostatsog/dockerNav/internal/client"
+	"github.com/Gostatsog/dockerNav/internal/shellwords"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
-	"github.com/docker/go-connections/nat"
 )
 
 // ContainerCreateMsg carries the result of container creation
@@ -39,8 +41,44 @@ type ContainerCreateModel struct {
 	volumes     string
 	envVars     string
 	command     string
-	networkName string
+	networkNames []string
+	endpoints   map[string]*endpointOptions
 	restart     string
+
+	// Advanced options, shown when showAdvanced is set
+	showAdvanced bool
+	advanced     advancedOptions
+
+	// docker run import prompt and the flags it could not map
+	importing   bool
+	importInput textarea.Model
+	importErr   string
+	unmapped    []string
+
+	// Template the form was filled from and the save-as-template prompt
+	templateName        string
+	templateDescription string
+	savingTemplate      bool
+	templateInput       textinput.Model
+	templateErr         string
+	overwrite           string
+	notice              string
+
+	// Create workflow: image pull, creation, start and followed logs
+	afterCreate  string
+	imageInput   *huh.Input
+	creating     bool
+	steps        []string
+	pull         *pullProgress
+	output       string
+	logView      viewport.Model
+	updates      <-chan tea.Msg
+	cancel       context.CancelFunc
+
+	// Host ports of running containers and the free port offered on conflict
+	published  []publishedPort
+	portsInput *huh.Input
+	portFix    *portFix
 }
 
 // NewContainerCreateModel creates a new container creation model
@@ -48,6 +86,8 @@ func NewContainerCreateModel(docker *client.DockerClient) *ContainerCreateModel
 	m := &ContainerCreateModel{
 		docker:     docker,
 		restart:    "no", // Default restart policy
+		afterCreate: afterCreateNone,
+		logView:    viewport.New(0, 0),
 	}
 	
 	return m
@@ -58,6 +98,7 @@ func (m *ContainerCreateModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.fetchNetworks(),
 		m.fetchImages(),
+		m.fetchPublishedPorts(),
 	)
 }
 
@@ -117,16 +158,11 @@ func (m *ContainerCreateModel) fetchImages() tea.Cmd {
 
 // initForm initializes the form with the fetched data
 func (m *ContainerCreateModel) initForm() {
-	// Create network options
-	networkOptions := []huh.Option[string]{}
-	for _, net := range m.networks {
-		networkOptions = append(networkOptions, huh.NewOption(net.Name, net.Name))
+	// Set default network to bridge unless networks were imported
+	if len(m.networkNames) == 0 {
+		m.networkNames = []string{"bridge"}
 	}
 	
-	// Set default network to bridge if available
-	defaultNetwork := "bridge"
-	m.networkName = defaultNetwork
-	
 	// Create restart policy options
 	restartOptions := []huh.Option[string]{
 		huh.NewOption("No restart", "no"),
@@ -135,169 +171,134 @@ func (m *ContainerCreateModel) initForm() {
 		huh.NewOption("Restart unless stopped", "unless-stopped"),
 	}
 	
+	// Any image may be typed; local images are offered as suggestions and
+	// missing ones are pulled when the container is created
+	m.imageInput = huh.NewInput().
+		Title("Image").
+		Placeholder("e.g., nginx:latest or ghcr.io/org/app:1.2").
+		Suggestions(m.images).
+		Value(&m.imageName).
+		Validate(validateImage)
+	
+	// Host ports are checked against running containers and this host
+	m.portsInput = huh.NewInput().
+		Title("Ports").
+		Placeholder("e.g., 8080:80 127.0.0.1:5353:53/udp 8000-8010:8000-8010").
+		Value(&m.ports).
+		Validate(m.validatePortField)
+	
 	// Create the form
-	m.form = huh.NewForm(
+	groups := []*huh.Group{
 		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title("Image").
-				Options(huh.NewOptions(m.images...)...).
-				Value(&m.imageName),
+			m.imageInput,
 			
 			huh.NewInput().
 				Title("Container Name").
 				Placeholder("my-container").
 				Value(&m.containerName),
 			
-			huh.NewInput().
-				Title("Ports").
-				Placeholder("host:container, e.g., 8080:80").
-				Value(&m.ports),
+			m.portsInput,
 			
 			huh.NewInput().
 				Title("Volumes").
-				Placeholder("host:container, e.g., ./data:/data").
-				Value(&m.volumes),
+				Placeholder("source:target[:options], e.g., ./data:/data pgdata:/var/lib/postgresql/data:rw").
+				Value(&m.volumes).
+				Validate(validateWith(parseVolumes)),
 			
 			huh.NewInput().
 				Title("Environment Variables").
-				Placeholder("KEY=VALUE, KEY2=VALUE2").
-				Value(&m.envVars),
+				Placeholder("KEY=VALUE 'GREETING=hello, world'").
+				Value(&m.envVars).
+				Validate(validateWith(parseEnv)),
 			
 			huh.NewInput().
 				Title("Command").
 				Placeholder("Command to run, e.g., nginx -g 'daemon off;'").
-				Value(&m.command),
+				Value(&m.command).
+				Validate(validateWith(shellwords.Split)),
 			
-			huh.NewSelect[string]().
-				Title("Network").
-				Options(networkOptions...).
-				Value(&m.networkName),
+			m.networkField(),
 			
 			huh.NewSelect[string]().
 				Title("Restart Policy").
 				Options(restartOptions...).
 				Value(&m.restart),
+
+			huh.NewSelect[string]().
+				Title("After Creating").
+				Options(
+					huh.NewOption("Leave the container stopped", afterCreateNone),
+					huh.NewOption("Start the container", afterCreateStart),
+					huh.NewOption("Start the container and follow its logs", afterCreateLogs),
+				).
+				Value(&m.afterCreate),
+
+			huh.NewConfirm().
+				Title("Configure advanced options?").
+				Description("Entrypoint, user, limits, capabilities, devices, logging, healthcheck and more").
+				Value(&m.showAdvanced),
 		),
-	).WithWidth(m.width - 4).WithShowHelp(true)
+	}
+	groups = append(groups, m.networkGroups()...)
+	groups = append(groups, m.advanced.advancedGroups(func() bool { return m.showAdvanced })...)
+
+	m.form = huh.NewForm(groups...).WithWidth(m.width - 4).WithShowHelp(true)
 }
 
-// createContainer creates a Docker container based on form input
-func (m *ContainerCreateModel) createContainer() tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		
-		// Parse ports
-		portBindings := nat.PortMap{}
-		exposedPorts := nat.PortSet{}
-		
-		if m.ports != "" {
-			for _, binding := range strings.Split(m.ports, ",") {
-				binding = strings.TrimSpace(binding)
-				parts := strings.Split(binding, ":")
-				
-				if len(parts) != 2 {
-					return ContainerCreateMsg{
-						Error: fmt.Errorf("invalid port format: %s, expected host:container", binding),
-					}
-				}
-				
-				hostPort := parts[0]
-				containerPort := parts[1]
-				
-				// Append protocol if not specified
-				if !strings.Contains(containerPort, "/") {
-					containerPort = containerPort + "/tcp"
-				}
-				
-				natPort, err := nat.NewPort(strings.Split(containerPort, "/")[1], strings.Split(containerPort, "/")[0])
-				if err != nil {
-					return ContainerCreateMsg{
-						Error: fmt.Errorf("invalid port: %s", err),
-					}
-				}
-				
-				portBindings[natPort] = []nat.PortBinding{
-					{
-						HostPort: hostPort,
-					},
-				}
-				
-				exposedPorts[natPort] = struct{}{}
-			}
-		}
-		
-		// Parse volumes
-		volumes := []string{}
-		if m.volumes != "" {
-			volumes = strings.Split(m.volumes, ",")
-			for i, v := range volumes {
-				volumes[i] = strings.TrimSpace(v)
-			}
-		}
-		
-		// Parse environment variables
-		env := []string{}
-		if m.envVars != "" {
-			env = strings.Split(m.envVars, ",")
-			for i, e := range env {
-				env[i] = strings.TrimSpace(e)
-			}
-		}
-		
-		// Parse command
-		var cmd []string
-		if m.command != "" {
-			cmd = strings.Fields(m.command)
-		}
-		
-		// Restart policy
-		restartPolicy := container.RestartPolicy{
-			Name: container.RestartPolicyMode(m.restart),
-		}
-		
-		// Create container config
-		config := &container.Config{
-			Image:        m.imageName,
-			ExposedPorts: exposedPorts,
-			Env:          env,
-			Cmd:          cmd,
-		}
-		
-		// Create host config
-		hostConfig := &container.HostConfig{
-			PortBindings: portBindings,
-			Binds:        volumes,
-			RestartPolicy: restartPolicy,
-		}
-		
-		// Create network config
-		networkConfig := &network.NetworkingConfig{
-			EndpointsConfig: map[string]*network.EndpointSettings{
-				m.networkName: {},
-			},
-		}
-		
-		// Create the container
-		resp, err := m.docker.Client.ContainerCreate(
-			ctx,
-			config,
-			hostConfig,
-			networkConfig,
-			nil,
-			m.containerName,
-		)
-		
-		if err != nil {
-			return ContainerCreateMsg{
-				Error: err,
-			}
-		}
-		
-		return ContainerCreateMsg{
-			ContainerID: resp.ID,
-			Error:       nil,
+// buildConfig turns the form values into the container configuration
+func (m *ContainerCreateModel) buildConfig() (*container.Config, *container.HostConfig, *network.NetworkingConfig, error) {
+	// Parse the fields; the form validators have already checked them
+	exposedPorts, portBindings, err := parsePorts(m.ports)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("ports: %w", err)
+	}
+	volumes, err := parseVolumes(m.volumes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("volumes: %w", err)
+	}
+	env, err := parseEnv(m.envVars)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("environment: %w", err)
+	}
+	cmd, err := shellwords.Split(m.command)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("command: %w", err)
+	}
+	
+	// Restart policy
+	restartPolicy := container.RestartPolicy{
+		Name: container.RestartPolicyMode(m.restart),
+	}
+	
+	// Create container config
+	config := &container.Config{
+		Image:        m.imageName,
+		ExposedPorts: exposedPorts,
+		Env:          env,
+		Cmd:          cmd,
+	}
+	
+	// Create host config
+	hostConfig := &container.HostConfig{
+		PortBindings: portBindings,
+		Binds:        volumes,
+		RestartPolicy: restartPolicy,
+	}
+	
+	// Apply advanced options
+	if m.showAdvanced {
+		if err := m.advanced.apply(config, hostConfig); err != nil {
+			return nil, nil, nil, err
 		}
 	}
+	
+	// Create network config
+	networkConfig, err := m.networkingConfig(hostConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	
+	return config, hostConfig, networkConfig, nil
 }
 
 // Update handles messages and updates the model
@@ -306,6 +307,10 @@ func (m *ContainerCreateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.importing {
+			m.importInput.SetWidth(msg.Width - 8)
+		}
+		m.resizeLogs()
 
 		// Update form width if it's initialized
 		if m.form != nil {
@@ -324,6 +329,13 @@ func (m *ContainerCreateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.error = msg.Error
 			return m, nil
 		}
+		if m.imageInput != nil {
+			m.imageInput.Suggestions(m.images)
+		}
+
+	case PublishedPortsMsg:
+		// Without the list, conflicts surface as API errors on create
+		m.published = msg.Ports
 
 	case ContainerCreateMsg:
 		if msg.Error != nil {
@@ -332,9 +344,34 @@ func (m *ContainerCreateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.result = fmt.Sprintf("Container created successfully with ID: %s", msg.ContainerID[:12])
+		if m.afterCreate == afterCreateLogs {
+			m.resizeLogs()
+			return m, waitForUpdate(m.updates)
+		}
+
+	case ContainerCreateProgressMsg, ContainerCreatePullMsg, ContainerCreateLogsMsg:
+		return m, m.updateCreate(msg)
 
 	// Check if form is completed instead of huh.FormSubmitMsg
 	default:
+		if m.creating {
+			var cmd tea.Cmd
+			m.logView, cmd = m.logView.Update(msg)
+			return m, cmd
+		}
+		if m.importing {
+			return m, m.updateImport(msg)
+		}
+		if m.savingTemplate {
+			return m, m.updateSaveTemplate(msg)
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "ctrl+s" && m.form != nil {
+			return m, m.startSaveTemplate()
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "ctrl+f" && m.portFix != nil {
+			m.applyPortFix()
+			return m, nil
+		}
 		if m.form != nil && m.form.State == huh.StateCompleted {
 			return m, m.createContainer()
 		}
@@ -358,6 +395,10 @@ func (m *ContainerCreateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 // View renders the current view
 func (m *ContainerCreateModel) View() string {
+	if m.creating {
+		return m.renderCreate()
+	}
+	
 	if m.error != nil {
 		errorBox := StyleInfoBox.
 			BorderForeground(ColorError).
@@ -388,24 +429,54 @@ func (m *ContainerCreateModel) View() string {
 		)
 	}
 	
+	if m.importing {
+		return m.renderImport()
+	}
+	
+	if m.savingTemplate {
+		return m.renderSaveTemplate()
+	}
+	
 	if m.form == nil {
 		return StyleMainLayout.Render("Loading...")
 	}
 	
 	title := StyleTitle.Render("Create Container")
+	if m.templateName != "" {
+		title = StyleTitle.Render(fmt.Sprintf("Create Container from %s", m.templateName))
+	}
 	formView := m.form.View()
 	
-	help := StyleHelp.Render(
-		"↑/↓: Navigate • Tab: Next Field • Enter: Submit • Esc: Back",
-	)
+	helpText := "↑/↓: Navigate • Tab: Next Field • Enter: Submit • ctrl+s: Save as template • Esc: Back"
+	if m.portFix != nil {
+		helpText = fmt.Sprintf("ctrl+f: Use host port %s • %s", m.portFix.to, helpText)
+	}
+	help := StyleHelp.Render(helpText)
 	
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		title,
-		"",
-		formView,
-		"",
-		help,
-	)
+	sections := []string{title, ""}
+	if m.notice != "" {
+		sections = append(sections, StyleSuccess.Render(m.notice), "")
+	}
+	if len(m.unmapped) > 0 {
+		sections = append(sections, m.renderUnmapped(), "")
+	}
+	sections = append(sections, formView, "", help)
+	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
 	
 	return StyleMainLayout.Render(content)
-}
\ No newline at end of file
+}
+
+// handlesEsc reports whether esc closes a prompt inside the form rather
+// than the form itself
+func (m *ContainerCreateModel) handlesEsc() bool {
+	return m.savingTemplate
+}
+
+// resizeLogs fits the followed log output below the create progress
+func (m *ContainerCreateModel) resizeLogs() {
+	m.logView.Width = m.width - 4
+	m.logView.Height = m.height - len(m.steps) - 14
+	if m.logView.Height < 5 {
+		m.logView.Height = 5
+	}
+}
@@ -0,0 +1,472 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/internal/shellwords"
+	"github.com/charmbracelet/huh"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+)
+
+// advancedOptions holds the values of the advanced create form groups. List
+// values are separated by spaces and may be shell-quoted.
+type advancedOptions struct {
+	entrypoint string
+	workingDir string
+	user       string
+	hostname   string
+	labels     string
+
+	memory     string
+	cpus       string
+	capAdd     string
+	capDrop    string
+	privileged bool
+	readOnly   bool
+	tmpfs      string
+	devices    string
+
+	extraHosts string
+	dns        string
+	logDriver  string
+	logOpts    string
+
+	healthCmd         string
+	healthInterval    string
+	healthTimeout     string
+	healthRetries     string
+	healthStartPeriod string
+	stopSignal        string
+	stopTimeout       string
+	autoRemove        bool
+}
+
+// advancedGroups returns the form groups for the advanced options. They are
+// skipped unless show reports true.
+func (a *advancedOptions) advancedGroups(show func() bool) []*huh.Group {
+	hide := func() bool { return !show() }
+
+	return []*huh.Group{
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Entrypoint").
+				Placeholder("Overrides the image entrypoint, e.g., /docker-entrypoint.sh").
+				Value(&a.entrypoint).
+				Validate(validateWith(shellwords.Split)),
+
+			huh.NewInput().
+				Title("Working Directory").
+				Placeholder("/app").
+				Value(&a.workingDir),
+
+			huh.NewInput().
+				Title("User").
+				Placeholder("uid[:gid] or name, e.g., 1000:1000").
+				Value(&a.user),
+
+			huh.NewInput().
+				Title("Hostname").
+				Value(&a.hostname),
+
+			huh.NewInput().
+				Title("Labels").
+				Placeholder("KEY=VALUE KEY2=VALUE2").
+				Value(&a.labels).
+				Validate(validateWith(parseKeyValueWords)),
+
+			huh.NewInput().
+				Title("Stop Signal").
+				Placeholder("SIGTERM").
+				Value(&a.stopSignal),
+
+			huh.NewInput().
+				Title("Stop Timeout").
+				Placeholder("Seconds to wait before killing, e.g., 30").
+				Value(&a.stopTimeout).
+				Validate(validateWith(parseSeconds)),
+
+			huh.NewConfirm().
+				Title("Remove container when it exits").
+				Value(&a.autoRemove),
+		).Title("Advanced: Runtime").WithHideFunc(hide),
+
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Memory Limit").
+				Placeholder("e.g., 512m or 2g").
+				Value(&a.memory).
+				Validate(validateWith(parseMemory)),
+
+			huh.NewInput().
+				Title("CPUs").
+				Placeholder("e.g., 1.5").
+				Value(&a.cpus).
+				Validate(validateWith(parseCPUs)),
+
+			huh.NewInput().
+				Title("Add Capabilities").
+				Placeholder("NET_ADMIN SYS_TIME").
+				Value(&a.capAdd).
+				Validate(validateWith(shellwords.Split)),
+
+			huh.NewInput().
+				Title("Drop Capabilities").
+				Placeholder("ALL").
+				Value(&a.capDrop).
+				Validate(validateWith(shellwords.Split)),
+
+			huh.NewConfirm().
+				Title("Privileged").
+				Value(&a.privileged),
+
+			huh.NewConfirm().
+				Title("Read-only Root Filesystem").
+				Value(&a.readOnly),
+
+			huh.NewInput().
+				Title("Tmpfs Mounts").
+				Placeholder("/run:rw,size=64m /tmp").
+				Value(&a.tmpfs).
+				Validate(validateWith(parseTmpfs)),
+
+			huh.NewInput().
+				Title("Devices").
+				Placeholder("/dev/snd /dev/sda:/dev/xvda:rwm").
+				Value(&a.devices).
+				Validate(validateWith(parseDevices)),
+		).Title("Advanced: Resources & Security").WithHideFunc(hide),
+
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Extra Hosts").
+				Placeholder("host.docker.internal:host-gateway db:10.0.0.5").
+				Value(&a.extraHosts).
+				Validate(validateWith(parseExtraHosts)),
+
+			huh.NewInput().
+				Title("DNS Servers").
+				Placeholder("1.1.1.1 8.8.8.8").
+				Value(&a.dns).
+				Validate(validateWith(parseDNS)),
+
+			huh.NewInput().
+				Title("Log Driver").
+				Placeholder("json-file, local, syslog, ...").
+				Value(&a.logDriver),
+
+			huh.NewInput().
+				Title("Log Options").
+				Placeholder("max-size=10m max-file=3").
+				Value(&a.logOpts).
+				Validate(validateWith(parseKeyValueWords)),
+
+			huh.NewInput().
+				Title("Healthcheck Command").
+				Placeholder("Run by the shell, e.g., curl -f http://localhost/ || exit 1").
+				Value(&a.healthCmd),
+
+			huh.NewInput().
+				Title("Healthcheck Interval").
+				Placeholder("e.g., 30s").
+				Value(&a.healthInterval).
+				Validate(validateWith(parseOptionalDuration)),
+
+			huh.NewInput().
+				Title("Healthcheck Timeout").
+				Placeholder("e.g., 5s").
+				Value(&a.healthTimeout).
+				Validate(validateWith(parseOptionalDuration)),
+
+			huh.NewInput().
+				Title("Healthcheck Retries").
+				Placeholder("e.g., 3").
+				Value(&a.healthRetries).
+				Validate(validateWith(parseRetries)),
+
+			huh.NewInput().
+				Title("Healthcheck Start Period").
+				Placeholder("e.g., 10s").
+				Value(&a.healthStartPeriod).
+				Validate(validateWith(parseOptionalDuration)),
+		).Title("Advanced: Networking, Logging & Health").WithHideFunc(hide),
+	}
+}
+
+// apply maps the advanced options onto the container configuration
+func (a *advancedOptions) apply(config *container.Config, hostConfig *container.HostConfig) error {
+	var err error
+
+	if config.Entrypoint, err = shellwords.Split(a.entrypoint); err != nil {
+		return fmt.Errorf("entrypoint: %w", err)
+	}
+	config.WorkingDir = strings.TrimSpace(a.workingDir)
+	config.User = strings.TrimSpace(a.user)
+	config.Hostname = strings.TrimSpace(a.hostname)
+	config.StopSignal = strings.TrimSpace(a.stopSignal)
+
+	labels, err := parseKeyValueWords(a.labels)
+	if err != nil {
+		return fmt.Errorf("labels: %w", err)
+	}
+	if len(labels) > 0 {
+		config.Labels = labels
+	}
+
+	if config.StopTimeout, err = parseSeconds(a.stopTimeout); err != nil {
+		return fmt.Errorf("stop timeout: %w", err)
+	}
+	if config.Healthcheck, err = a.healthcheck(); err != nil {
+		return err
+	}
+
+	if hostConfig.Memory, err = parseMemory(a.memory); err != nil {
+		return fmt.Errorf("memory: %w", err)
+	}
+	if hostConfig.NanoCPUs, err = parseCPUs(a.cpus); err != nil {
+		return fmt.Errorf("cpus: %w", err)
+	}
+	if hostConfig.CapAdd, err = shellwords.Split(a.capAdd); err != nil {
+		return fmt.Errorf("add capabilities: %w", err)
+	}
+	if hostConfig.CapDrop, err = shellwords.Split(a.capDrop); err != nil {
+		return fmt.Errorf("drop capabilities: %w", err)
+	}
+	hostConfig.Privileged = a.privileged
+	hostConfig.ReadonlyRootfs = a.readOnly
+	hostConfig.AutoRemove = a.autoRemove
+
+	if hostConfig.ExtraHosts, err = parseExtraHosts(a.extraHosts); err != nil {
+		return fmt.Errorf("extra hosts: %w", err)
+	}
+	if hostConfig.DNS, err = parseDNS(a.dns); err != nil {
+		return fmt.Errorf("dns: %w", err)
+	}
+	if hostConfig.Tmpfs, err = parseTmpfs(a.tmpfs); err != nil {
+		return fmt.Errorf("tmpfs: %w", err)
+	}
+	if hostConfig.Devices, err = parseDevices(a.devices); err != nil {
+		return err
+	}
+
+	if a.logDriver != "" || a.logOpts != "" {
+		opts, err := parseKeyValueWords(a.logOpts)
+		if err != nil {
+			return fmt.Errorf("log options: %w", err)
+		}
+		hostConfig.LogConfig = container.LogConfig{
+			Type:   strings.TrimSpace(a.logDriver),
+			Config: opts,
+		}
+	}
+
+	return nil
+}
+
+// healthcheck builds the healthcheck config, nil when no command is set
+func (a *advancedOptions) healthcheck() (*container.HealthConfig, error) {
+	if strings.TrimSpace(a.healthCmd) == "" {
+		return nil, nil
+	}
+
+	health := &container.HealthConfig{
+		Test: []string{"CMD-SHELL", strings.TrimSpace(a.healthCmd)},
+	}
+
+	var err error
+	if health.Interval, err = parseOptionalDuration(a.healthInterval); err != nil {
+		return nil, fmt.Errorf("healthcheck interval: %w", err)
+	}
+	if health.Timeout, err = parseOptionalDuration(a.healthTimeout); err != nil {
+		return nil, fmt.Errorf("healthcheck timeout: %w", err)
+	}
+	if health.StartPeriod, err = parseOptionalDuration(a.healthStartPeriod); err != nil {
+		return nil, fmt.Errorf("healthcheck start period: %w", err)
+	}
+	if health.Retries, err = parseRetries(a.healthRetries); err != nil {
+		return nil, fmt.Errorf("healthcheck retries: %w", err)
+	}
+
+	return health, nil
+}
+
+// parseKeyValueWords turns shell-quoted KEY=VALUE words into a map
+func parseKeyValueWords(value string) (map[string]string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for _, word := range words {
+		k, v, ok := strings.Cut(word, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%q is not KEY=VALUE", word)
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+// parseMemory parses a memory size such as 512m, zero when empty
+func parseMemory(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return units.RAMInBytes(value)
+}
+
+// parseCPUs parses a fractional CPU count into nano CPUs, zero when empty
+func parseCPUs(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	cpus, err := strconv.ParseFloat(value, 64)
+	if err != nil || cpus <= 0 {
+		return 0, fmt.Errorf("%q is not a positive number", value)
+	}
+	return int64(cpus * 1e9), nil
+}
+
+// parseSeconds parses a number of seconds, nil when empty
+func parseSeconds(value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return nil, fmt.Errorf("%q is not a number of seconds", value)
+	}
+	return &seconds, nil
+}
+
+// parseOptionalDuration parses a duration such as 30s, zero when empty
+func parseOptionalDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// parseRetries parses a retry count, zero when empty
+func parseRetries(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return retries, nil
+}
+
+// parseTmpfs parses path[:options] words into a tmpfs map
+func parseTmpfs(value string) (map[string]string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil || len(words) == 0 {
+		return nil, err
+	}
+
+	tmpfs := map[string]string{}
+	for _, word := range words {
+		path, opts, _ := strings.Cut(word, ":")
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("%q: path must be absolute", word)
+		}
+		tmpfs[path] = opts
+	}
+	return tmpfs, nil
+}
+
+// parseExtraHosts parses host:ip words
+func parseExtraHosts(value string) ([]string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range words {
+		host, ip, ok := strings.Cut(word, ":")
+		if !ok || host == "" || ip == "" {
+			return nil, fmt.Errorf("%q: expected host:ip", word)
+		}
+		if ip != "host-gateway" && net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("%q: %q is not an IP address", word, ip)
+		}
+	}
+	return words, nil
+}
+
+// parseDNS parses DNS server addresses
+func parseDNS(value string) ([]string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range words {
+		if net.ParseIP(word) == nil {
+			return nil, fmt.Errorf("%q is not an IP address", word)
+		}
+	}
+	return words, nil
+}
+
+// parseDevices parses device mapping words
+func parseDevices(value string) ([]container.DeviceMapping, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []container.DeviceMapping
+	for _, word := range words {
+		device, err := parseDevice(word)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// parseDevice parses a device in host[:container[:permissions]] form
+func parseDevice(spec string) (container.DeviceMapping, error) {
+	parts := strings.Split(spec, ":")
+	device := container.DeviceMapping{
+		PathOnHost:        parts[0],
+		PathInContainer:   parts[0],
+		CgroupPermissions: "rwm",
+	}
+
+	switch len(parts) {
+	case 1:
+	case 2:
+		// The second part is either a path or the permissions
+		if strings.HasPrefix(parts[1], "/") {
+			device.PathInContainer = parts[1]
+		} else {
+			device.CgroupPermissions = parts[1]
+		}
+	case 3:
+		device.PathInContainer = parts[1]
+		device.CgroupPermissions = parts[2]
+	default:
+		return device, fmt.Errorf("device %q: expected host[:container[:permissions]]", spec)
+	}
+
+	if !strings.HasPrefix(device.PathOnHost, "/") || !strings.HasPrefix(device.PathInContainer, "/") {
+		return device, fmt.Errorf("device %q: paths must be absolute", spec)
+	}
+	if strings.Trim(device.CgroupPermissions, "rwm") != "" {
+		return device, fmt.Errorf("device %q: permissions must be a combination of r, w and m", spec)
+	}
+	return device, nil
+}
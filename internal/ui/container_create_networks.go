@@ -0,0 +1,260 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/shellwords"
+	"github.com/charmbracelet/huh"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+// endpointOptions holds the per-network values of the create form
+type endpointOptions struct {
+	aliases string
+	ipv4    string
+	ipv6    string
+	links   string
+}
+
+// endpoint returns the options for a network, creating them if needed
+func (m *ContainerCreateModel) endpoint(name string) *endpointOptions {
+	if m.endpoints == nil {
+		m.endpoints = map[string]*endpointOptions{}
+	}
+	ep, ok := m.endpoints[name]
+	if !ok {
+		ep = &endpointOptions{}
+		m.endpoints[name] = ep
+	}
+	return ep
+}
+
+// networkSummary returns the fetched network with the given name
+func (m *ContainerCreateModel) networkSummary(name string) (network.Summary, bool) {
+	for _, n := range m.networks {
+		if n.Name == name {
+			return n, true
+		}
+	}
+	return network.Summary{}, false
+}
+
+// networkChoices returns the names offered in the Networks field: the
+// fetched networks followed by selected ones that do not exist
+func (m *ContainerCreateModel) networkChoices() []string {
+	names := make([]string, 0, len(m.networks))
+	for _, n := range m.networks {
+		names = append(names, n.Name)
+	}
+	for _, name := range m.networkNames {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// networkField returns the multi-select for the networks to attach
+func (m *ContainerCreateModel) networkField() huh.Field {
+	options := []huh.Option[string]{}
+	for _, name := range m.networkChoices() {
+		label := name
+		if _, ok := m.networkSummary(name); !ok {
+			label += " (not found)"
+		}
+		options = append(options, huh.NewOption(label, name))
+	}
+
+	return huh.NewMultiSelect[string]().
+		Title("Networks").
+		Description("The first network is attached at creation, the others are connected afterwards").
+		Options(options...).
+		Value(&m.networkNames).
+		Validate(validateNetworkSelection)
+}
+
+// validateNetworkSelection rejects combining host or none with other networks
+func validateNetworkSelection(names []string) error {
+	for _, name := range names {
+		if (name == "host" || name == "none") && len(names) > 1 {
+			return fmt.Errorf("the %s network cannot be combined with other networks", name)
+		}
+	}
+	return nil
+}
+
+// networkGroups returns a form group per network for its aliases, static
+// addresses and links. A group is only shown while its network is selected.
+func (m *ContainerCreateModel) networkGroups() []*huh.Group {
+	var groups []*huh.Group
+	for _, name := range m.networkChoices() {
+		// host and none have no endpoint settings
+		if name == "host" || name == "none" {
+			continue
+		}
+		name := name
+		ep := m.endpoint(name)
+
+		var fields []huh.Field
+		// The default bridge only supports legacy links
+		if name != "bridge" {
+			fields = append(fields,
+				huh.NewInput().
+					Title("Aliases").
+					Placeholder("Names other containers on this network can use, e.g., db primary").
+					Value(&ep.aliases).
+					Validate(validateWith(shellwords.Split)),
+
+				huh.NewInput().
+					Title("IPv4 Address").
+					Placeholder("Static address, empty to assign one automatically").
+					Value(&ep.ipv4).
+					Validate(m.validateAddress(name, false)),
+
+				huh.NewInput().
+					Title("IPv6 Address").
+					Placeholder("Static address, empty to assign one automatically").
+					Value(&ep.ipv6).
+					Validate(m.validateAddress(name, true)),
+			)
+		}
+		fields = append(fields,
+			huh.NewInput().
+				Title("Links").
+				Placeholder("container[:alias] ..., e.g., postgres:db").
+				Value(&ep.links).
+				Validate(validateWith(parseLinks)),
+		)
+
+		groups = append(groups, huh.NewGroup(fields...).
+			Title(fmt.Sprintf("Network: %s", name)).
+			Description(m.subnetDescription(name)).
+			WithHideFunc(func() bool { return !slices.Contains(m.networkNames, name) }))
+	}
+	return groups
+}
+
+// subnetDescription lists the IPAM subnets of a network
+func (m *ContainerCreateModel) subnetDescription(name string) string {
+	summary, ok := m.networkSummary(name)
+	if !ok {
+		return "Network not found; it must exist before the container is created"
+	}
+	var subnets []string
+	for _, cfg := range summary.IPAM.Config {
+		if cfg.Subnet != "" {
+			subnets = append(subnets, cfg.Subnet)
+		}
+	}
+	if len(subnets) == 0 {
+		return fmt.Sprintf("Driver %s, no subnets configured", summary.Driver)
+	}
+	return fmt.Sprintf("Driver %s, subnets %s", summary.Driver, strings.Join(subnets, ", "))
+}
+
+// validateAddress returns a validator checking that a static address
+// belongs to one of the network's IPAM subnets of the same family
+func (m *ContainerCreateModel) validateAddress(name string, v6 bool) func(string) error {
+	family := "IPv4"
+	if v6 {
+		family = "IPv6"
+	}
+
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		ip := net.ParseIP(value)
+		if ip == nil || (ip.To4() == nil) != v6 {
+			return fmt.Errorf("%q is not an %s address", value, family)
+		}
+
+		summary, ok := m.networkSummary(name)
+		if !ok {
+			return nil
+		}
+		var subnets []string
+		for _, cfg := range summary.IPAM.Config {
+			_, subnet, err := net.ParseCIDR(cfg.Subnet)
+			if err != nil || (subnet.IP.To4() == nil) != v6 {
+				continue
+			}
+			if cfg.Gateway != "" && ip.Equal(net.ParseIP(cfg.Gateway)) {
+				return fmt.Errorf("%s is the gateway of %s", value, name)
+			}
+			if subnet.Contains(ip) {
+				return nil
+			}
+			subnets = append(subnets, cfg.Subnet)
+		}
+		if len(subnets) == 0 {
+			return fmt.Errorf("network %s has no %s subnet", name, family)
+		}
+		return fmt.Errorf("%s is outside the %s subnets of %s: %s", value, family, name, strings.Join(subnets, ", "))
+	}
+}
+
+// parseLinks parses shell-quoted container[:alias] links into the
+// name:alias form of the Docker API
+func parseLinks(value string) ([]string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]string, 0, len(words))
+	for _, word := range words {
+		name, alias, hasAlias := strings.Cut(word, ":")
+		if name == "" || (hasAlias && alias == "") || strings.Contains(alias, ":") {
+			return nil, fmt.Errorf("%q is not container[:alias]", word)
+		}
+		if !hasAlias {
+			alias = name
+		}
+		links = append(links, name+":"+alias)
+	}
+	return links, nil
+}
+
+// networkingConfig builds the endpoint settings of the selected networks.
+// Links on the default bridge are legacy links set on the host config.
+func (m *ContainerCreateModel) networkingConfig(hostConfig *container.HostConfig) (*network.NetworkingConfig, error) {
+	config := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{},
+	}
+	if len(m.networkNames) == 0 {
+		return config, nil
+	}
+	hostConfig.NetworkMode = container.NetworkMode(m.networkNames[0])
+
+	for _, name := range m.networkNames {
+		ep := m.endpoint(name)
+		aliases, err := shellwords.Split(ep.aliases)
+		if err != nil {
+			return nil, fmt.Errorf("network %s aliases: %w", name, err)
+		}
+		links, err := parseLinks(ep.links)
+		if err != nil {
+			return nil, fmt.Errorf("network %s links: %w", name, err)
+		}
+
+		settings := &network.EndpointSettings{Aliases: aliases}
+		if ep.ipv4 != "" || ep.ipv6 != "" {
+			settings.IPAMConfig = &network.EndpointIPAMConfig{
+				IPv4Address: ep.ipv4,
+				IPv6Address: ep.ipv6,
+			}
+		}
+		if name == "bridge" {
+			hostConfig.Links = append(hostConfig.Links, links...)
+		} else {
+			settings.Links = links
+		}
+		config.EndpointsConfig[name] = settings
+	}
+	return config, nil
+}
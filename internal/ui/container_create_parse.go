@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/shellwords"
+	"github.com/docker/go-connections/nat"
+)
+
+// volumeNamePattern matches the names Docker accepts for named volumes
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// bindOptions are the mode flags accepted after a bind or volume target
+var bindOptions = map[string]bool{
+	"ro": true, "rw": true, "z": true, "Z": true, "nocopy": true,
+	"consistent": true, "cached": true, "delegated": true,
+	"shared": true, "rshared": true, "slave": true, "rslave": true, "private": true, "rprivate": true,
+}
+
+// parsePorts parses port specs in docker run -p syntax. Specs are separated
+// by spaces or commas and may use ranges, host IPs and protocols, e.g.
+// 8000-8010:8000-8010 or 127.0.0.1:8080:80/udp.
+func parsePorts(value string) (nat.PortSet, nat.PortMap, error) {
+	exposed, bindings, err := nat.ParsePortSpecs(splitPortSpecs(value))
+	if err != nil {
+		return nil, nil, err
+	}
+	return exposed, bindings, nil
+}
+
+// parseEnv parses shell-quoted KEY=VALUE words. A bare KEY takes its value
+// from the environment dockerNav runs in, like docker run -e KEY.
+func parseEnv(value string) ([]string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make([]string, 0, len(words))
+	for _, word := range words {
+		key, _, hasValue := strings.Cut(word, "=")
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%q is not KEY=VALUE", word)
+		}
+		if !hasValue {
+			v, ok := os.LookupEnv(key)
+			if !ok {
+				continue
+			}
+			word = key + "=" + v
+		}
+		env = append(env, word)
+	}
+	return env, nil
+}
+
+// parseVolumes parses shell-quoted mounts in source:target[:options] form.
+// Relative and ~ sources are resolved to absolute bind paths, other sources
+// must be valid volume names. A lone absolute path is an anonymous volume.
+func parseVolumes(value string) ([]string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+
+	binds := make([]string, 0, len(words))
+	for _, word := range words {
+		bind, err := parseBind(word)
+		if err != nil {
+			return nil, err
+		}
+		binds = append(binds, bind)
+	}
+	return binds, nil
+}
+
+// parseBind validates and normalises a single mount spec
+func parseBind(spec string) (string, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("%q: expected source:target[:options]", spec)
+	}
+
+	if len(parts) == 1 {
+		if !filepath.IsAbs(parts[0]) {
+			return "", fmt.Errorf("%q: an anonymous volume needs an absolute container path", spec)
+		}
+		return spec, nil
+	}
+
+	source, target := parts[0], parts[1]
+	if source == "" {
+		return "", fmt.Errorf("%q: source is empty", spec)
+	}
+	if !filepath.IsAbs(target) {
+		return "", fmt.Errorf("%q: container path %q must be absolute", spec, target)
+	}
+
+	switch {
+	case strings.HasPrefix(source, "~"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		source = filepath.Join(home, strings.TrimPrefix(source, "~"))
+	case strings.HasPrefix(source, "."):
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return "", err
+		}
+		source = abs
+	case filepath.IsAbs(source):
+	case !volumeNamePattern.MatchString(source):
+		return "", fmt.Errorf("%q: %q is neither a path nor a valid volume name", spec, source)
+	}
+
+	if len(parts) == 3 {
+		for _, opt := range strings.Split(parts[2], ",") {
+			if !bindOptions[opt] {
+				return "", fmt.Errorf("%q: unknown mount option %q", spec, opt)
+			}
+		}
+		return source + ":" + target + ":" + parts[2], nil
+	}
+	return source + ":" + target, nil
+}
+
+// validateWith adapts a parser into a huh field validator
+func validateWith[T any](parse func(string) (T, error)) func(string) error {
+	return func(value string) error {
+		_, err := parse(value)
+		return err
+	}
+}
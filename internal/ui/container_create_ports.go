@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// publishedPort is a host port published by an existing container
+type publishedPort struct {
+	ip    string
+	port  int
+	proto string
+	owner string // container name and mapping, e.g. "web (8080->80/tcp)"
+}
+
+// PublishedPortsMsg carries the host ports published by running containers
+type PublishedPortsMsg struct {
+	Ports []publishedPort
+	Error error
+}
+
+// portFix is a free host port offered in place of a conflicting one
+type portFix struct {
+	binding nat.PortMapping
+	to      string
+}
+
+// fetchPublishedPorts lists the host ports held by running containers
+func (m *ContainerCreateModel) fetchPublishedPorts() tea.Cmd {
+	return func() tea.Msg {
+		containers, err := m.docker.Client.ContainerList(context.Background(), container.ListOptions{})
+		if err != nil {
+			return PublishedPortsMsg{Error: err}
+		}
+
+		var ports []publishedPort
+		for _, c := range containers {
+			name := strings.TrimPrefix(c.ID[:12], "/")
+			if len(c.Names) > 0 {
+				name = strings.TrimPrefix(c.Names[0], "/")
+			}
+			for _, p := range c.Ports {
+				if p.PublicPort == 0 {
+					continue
+				}
+				ports = append(ports, publishedPort{
+					ip:    p.IP,
+					port:  int(p.PublicPort),
+					proto: p.Type,
+					owner: fmt.Sprintf("%s (%d->%d/%s)", name, p.PublicPort, p.PrivatePort, p.Type),
+				})
+			}
+		}
+		return PublishedPortsMsg{Ports: ports}
+	}
+}
+
+// sameHostIP reports whether two host IPs overlap; an unspecified address
+// binds every interface
+func sameHostIP(a, b string) bool {
+	unspecified := func(ip string) bool {
+		return ip == "" || ip == "0.0.0.0" || ip == "::"
+	}
+	return unspecified(a) || unspecified(b) || a == b
+}
+
+// localDaemon reports whether the Docker daemon runs on this machine, so
+// that ports bound on this host are the ones containers would collide with
+func (m *ContainerCreateModel) localDaemon() bool {
+	host := m.docker.Client.DaemonHost()
+	return strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://")
+}
+
+// hostPortBound reports whether a process on this host holds the port
+func hostPortBound(ip string, port int, proto string) bool {
+	address := net.JoinHostPort(strings.Trim(ip, "[]"), strconv.Itoa(port))
+
+	var err error
+	if proto == "udp" {
+		var conn net.PacketConn
+		if conn, err = net.ListenPacket("udp", address); err == nil {
+			conn.Close()
+		}
+	} else {
+		var listener net.Listener
+		if listener, err = net.Listen("tcp", address); err == nil {
+			listener.Close()
+		}
+	}
+	// Privileged ports cannot be probed without root; assume they are free
+	return err != nil && !errors.Is(err, os.ErrPermission)
+}
+
+// portOwner returns what holds a host port, or "" when it is free. Ports
+// already claimed by the form itself are passed in claimed.
+func (m *ContainerCreateModel) portOwner(ip string, port int, proto string, claimed []publishedPort) string {
+	for _, p := range claimed {
+		if p.port == port && p.proto == proto && sameHostIP(p.ip, ip) {
+			return p.owner
+		}
+	}
+	for _, p := range m.published {
+		if p.port == port && p.proto == proto && sameHostIP(p.ip, ip) {
+			return "container " + p.owner
+		}
+	}
+	if m.localDaemon() && hostPortBound(ip, port, proto) {
+		return "another process on this host"
+	}
+	return ""
+}
+
+// nextFreePort returns the first port after port that nothing holds
+func (m *ContainerCreateModel) nextFreePort(ip string, port int, proto string, claimed []publishedPort) int {
+	for p := port + 1; p <= 65535; p++ {
+		if m.portOwner(ip, p, proto, claimed) == "" {
+			return p
+		}
+	}
+	return 0
+}
+
+// validatePortField checks the port specs and their host ports. The first
+// conflict is reported with a free port that ctrl+f substitutes.
+func (m *ContainerCreateModel) validatePortField(value string) error {
+	_, bindings, err := parsePorts(value)
+	if err != nil {
+		return err
+	}
+	m.portFix = nil
+
+	// Check in a stable order so the reported conflict does not change
+	containerPorts := make([]nat.Port, 0, len(bindings))
+	for port := range bindings {
+		containerPorts = append(containerPorts, port)
+	}
+	sort.Slice(containerPorts, func(i, j int) bool {
+		return containerPorts[i].Int() < containerPorts[j].Int()
+	})
+
+	// Every fixed host port of the form, so the offered port is not one the
+	// form requests further on
+	var requested []publishedPort
+	for _, containerPort := range containerPorts {
+		for _, binding := range bindings[containerPort] {
+			if hostPort, err := strconv.Atoi(binding.HostPort); err == nil {
+				requested = append(requested, publishedPort{
+					ip:    binding.HostIP,
+					port:  hostPort,
+					proto: containerPort.Proto(),
+					owner: fmt.Sprintf("another mapping in this form (%d->%s)", hostPort, containerPort),
+				})
+			}
+		}
+	}
+
+	var claimed []publishedPort
+	for _, containerPort := range containerPorts {
+		for _, binding := range bindings[containerPort] {
+			// Ephemeral ports are assigned by Docker
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err != nil {
+				continue
+			}
+			proto := containerPort.Proto()
+
+			if owner := m.portOwner(binding.HostIP, hostPort, proto, claimed); owner != "" {
+				free := m.nextFreePort(binding.HostIP, hostPort, proto, requested)
+				if free == 0 {
+					return fmt.Errorf("host port %d/%s is used by %s", hostPort, proto, owner)
+				}
+				mapping := nat.PortMapping{Port: containerPort, Binding: binding}
+				if portSpecIndex(value, mapping) < 0 {
+					return fmt.Errorf("host port %d/%s is used by %s; %d is free", hostPort, proto, owner, free)
+				}
+				m.portFix = &portFix{binding: mapping, to: strconv.Itoa(free)}
+				return fmt.Errorf("host port %d/%s is used by %s; press ctrl+f to use %d", hostPort, proto, owner, free)
+			}
+			claimed = append(claimed, publishedPort{
+				ip:    binding.HostIP,
+				port:  hostPort,
+				proto: proto,
+				owner: fmt.Sprintf("another mapping in this form (%d->%s)", hostPort, containerPort),
+			})
+		}
+	}
+	return nil
+}
+
+// splitPortSpecs splits a port field into its specs
+func splitPortSpecs(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// portSpecIndex returns the index of the spec that publishes only the given
+// mapping, or -1. Specs with ranges cover several ports and are not rewritten.
+func portSpecIndex(value string, mapping nat.PortMapping) int {
+	for i, spec := range splitPortSpecs(value) {
+		mappings, err := nat.ParsePortSpec(spec)
+		if err == nil && len(mappings) == 1 && mappings[0] == mapping {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyPortFix replaces the conflicting host port with the offered free one
+func (m *ContainerCreateModel) applyPortFix() {
+	fix := m.portFix
+	from := fix.binding.Binding.HostPort
+
+	specs := splitPortSpecs(m.ports)
+	if i := portSpecIndex(m.ports, fix.binding); i >= 0 {
+		specs[i] = replaceHostPort(specs[i], from, fix.to)
+	}
+
+	m.ports = strings.Join(specs, " ")
+	m.portsInput.Value(&m.ports)
+	m.portFix = nil
+	m.notice = fmt.Sprintf("Using host port %s instead of %s", fix.to, from)
+}
+
+// replaceHostPort swaps the host port of a [ip:]host:container[/proto] spec
+func replaceHostPort(spec, from, to string) string {
+	i := strings.LastIndex(spec, ":")
+	if i < 0 {
+		return spec
+	}
+	prefix, rest := spec[:i], spec[i:]
+	j := strings.LastIndex(prefix, ":")
+	if prefix[j+1:] != from {
+		return spec
+	}
+	return prefix[:j+1] + to + rest
+}
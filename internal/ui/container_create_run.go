@@ -0,0 +1,259 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/registryauth"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// What to do once the container is created
+const (
+	afterCreateNone  = "create"
+	afterCreateStart = "start"
+	afterCreateLogs  = "logs"
+)
+
+// ContainerCreateProgressMsg carries a step of the create workflow
+type ContainerCreateProgressMsg struct {
+	Step string
+}
+
+// ContainerCreatePullMsg carries a progress message of the image pull
+type ContainerCreatePullMsg struct {
+	Message jsonmessage.JSONMessage
+}
+
+// ContainerCreateLogsMsg carries output of the created container
+type ContainerCreateLogsMsg struct {
+	Output string
+}
+
+// validateImage checks an image reference typed into the form
+func validateImage(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("an image is required")
+	}
+	_, err := reference.ParseNormalizedNamed(value)
+	return err
+}
+
+// createContainer starts the create workflow and returns the command
+// waiting for its first message
+func (m *ContainerCreateModel) createContainer() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.creating = true
+	m.steps = nil
+	m.pull = nil
+	m.output = ""
+	m.updates = m.runCreate(ctx)
+	return waitForUpdate(m.updates)
+}
+
+// runCreate pulls the image if it is missing, creates the container and
+// optionally starts it and follows its logs. Messages are sent on the
+// returned channel, which is closed when the workflow ends or ctx is done.
+func (m *ContainerCreateModel) runCreate(ctx context.Context) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+
+		send := func(msg tea.Msg) bool {
+			select {
+			case updates <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		step := func(format string, args ...interface{}) {
+			send(ContainerCreateProgressMsg{Step: fmt.Sprintf(format, args...)})
+		}
+
+		id, err := m.create(ctx, step, func(msg jsonmessage.JSONMessage) {
+			send(ContainerCreatePullMsg{Message: msg})
+		})
+		if !send(ContainerCreateMsg{ContainerID: id, Error: err}) || err != nil || m.afterCreate != afterCreateLogs {
+			return
+		}
+		if err := m.followLogs(ctx, id, send); err != nil && ctx.Err() == nil {
+			step("Log stream ended: %v", err)
+		}
+	}()
+
+	return updates
+}
+
+// create runs the pull, create and start steps
+func (m *ContainerCreateModel) create(ctx context.Context, step func(string, ...interface{}), pull func(jsonmessage.JSONMessage)) (string, error) {
+	config, hostConfig, networkConfig, err := m.buildConfig()
+	if err != nil {
+		return "", err
+	}
+
+	if err := m.ensureImage(ctx, step, pull); err != nil {
+		return "", err
+	}
+
+	// Attach the first network at creation and connect the others
+	// afterwards, as daemons before API 1.44 accept only one endpoint
+	var extraNames []string
+	if len(m.networkNames) > 1 {
+		extraNames = m.networkNames[1:]
+	}
+	extra := map[string]*network.EndpointSettings{}
+	for _, name := range extraNames {
+		extra[name] = networkConfig.EndpointsConfig[name]
+		delete(networkConfig.EndpointsConfig, name)
+	}
+
+	step("Creating container")
+	resp, err := m.docker.Client.ContainerCreate(ctx, config, hostConfig, networkConfig, nil, m.containerName)
+	if err != nil {
+		return "", err
+	}
+	for _, warning := range resp.Warnings {
+		step("Warning: %s", warning)
+	}
+
+	for _, name := range extraNames {
+		step("Connecting to network %s", name)
+		if err := m.docker.Client.NetworkConnect(ctx, name, resp.ID, extra[name]); err != nil {
+			return resp.ID, fmt.Errorf("created %s but could not connect it to %s: %w", resp.ID[:12], name, err)
+		}
+	}
+
+	if m.afterCreate == afterCreateNone {
+		return resp.ID, nil
+	}
+	step("Starting container %s", resp.ID[:12])
+	if err := m.docker.Client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return resp.ID, fmt.Errorf("created %s but could not start it: %w", resp.ID[:12], err)
+	}
+	return resp.ID, nil
+}
+
+// ensureImage pulls the image when it is not available locally
+func (m *ContainerCreateModel) ensureImage(ctx context.Context, step func(string, ...interface{}), pull func(jsonmessage.JSONMessage)) error {
+	_, err := m.docker.Client.ImageInspect(ctx, m.imageName)
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	step("Image %s not found locally, pulling", m.imageName)
+	options, err := registryauth.PullOptions(m.imageName)
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", m.imageName, err)
+	}
+	reader, err := m.docker.Client.ImagePull(ctx, m.imageName, options)
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", m.imageName, err)
+	}
+	defer reader.Close()
+
+	if err := decodePullStream(reader, pull); err != nil {
+		return fmt.Errorf("pull %s: %w", m.imageName, err)
+	}
+	step("Pulled %s", m.imageName)
+	return nil
+}
+
+// followLogs streams the output of the container until it exits or ctx is done
+func (m *ContainerCreateModel) followLogs(ctx context.Context, id string, send func(tea.Msg) bool) error {
+	logs, err := m.docker.Client.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	w := logWriter(func(p []byte) bool {
+		return send(ContainerCreateLogsMsg{Output: string(p)})
+	})
+	_, err = stdcopy.StdCopy(w, w, logs)
+	return err
+}
+
+// logWriter forwards written output, failing once the receiver is gone
+type logWriter func(p []byte) bool
+
+// Write implements io.Writer
+func (w logWriter) Write(p []byte) (int, error) {
+	if !w(p) {
+		return 0, context.Canceled
+	}
+	return len(p), nil
+}
+
+// stop cancels a running create workflow or log stream
+func (m *ContainerCreateModel) stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// updateCreate handles messages of the create workflow
+func (m *ContainerCreateModel) updateCreate(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case ContainerCreateProgressMsg:
+		m.steps = append(m.steps, msg.Step)
+	case ContainerCreatePullMsg:
+		if m.pull == nil {
+			m.pull = newPullProgress()
+		}
+		m.pull.update(msg.Message)
+	case ContainerCreateLogsMsg:
+		atBottom := m.logView.AtBottom()
+		m.output += msg.Output
+		m.logView.SetContent(m.output)
+		if atBottom {
+			m.logView.GotoBottom()
+		}
+	}
+	return waitForUpdate(m.updates)
+}
+
+// renderCreate renders the progress of the create workflow
+func (m *ContainerCreateModel) renderCreate() string {
+	sections := []string{StyleTitle.Render(fmt.Sprintf("Create Container: %s", m.imageName))}
+	if len(m.steps) > 0 {
+		sections = append(sections, strings.Join(m.steps, "\n"))
+	}
+	if m.pull != nil {
+		sections = append(sections, "", m.pull.View())
+	}
+
+	footer := "Working... • Esc: Cancel"
+	switch {
+	case m.error != nil:
+		sections = append(sections, "", StyleError.Render(fmt.Sprintf("Error: %v", m.error)))
+		footer = "Press esc to go back"
+	case m.result != "":
+		sections = append(sections, "", StyleSuccess.Render(m.result))
+		footer = "Press esc to go back"
+		if m.afterCreate == afterCreateLogs {
+			sections = append(sections, "", m.logView.View())
+			footer = "↑/↓: Scroll • Esc: Stop following and go back"
+		}
+	}
+	sections = append(sections, "", StyleHelp.Render(footer))
+
+	return StyleMainLayout.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
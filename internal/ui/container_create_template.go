@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/templates"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// template returns the current form values as a template. The values are
+// literal, so their $ signs are escaped; only variables typed in the
+// template editor are substituted.
+func (m *ContainerCreateModel) template(name string) *templates.Template {
+	t := &templates.Template{
+		Name:          name,
+		Description:   m.templateDescription,
+		Image:         m.imageName,
+		ContainerName: m.containerName,
+		Ports:         m.ports,
+		Volumes:       m.volumes,
+		Environment:   m.envVars,
+		Command:       m.command,
+		Network:       strings.Join(m.networkNames, " "),
+		Restart:       m.restart,
+	}
+	for _, name := range m.networkNames {
+		ep := m.endpoint(name)
+		if *ep != (endpointOptions{}) {
+			if t.Endpoints == nil {
+				t.Endpoints = map[string]*templates.Endpoint{}
+			}
+			t.Endpoints[name] = &templates.Endpoint{
+				Aliases: ep.aliases,
+				IPv4:    ep.ipv4,
+				IPv6:    ep.ipv6,
+				Links:   ep.links,
+			}
+		}
+	}
+	if m.showAdvanced {
+		a := m.advanced
+		t.Advanced = &templates.Advanced{
+			Entrypoint:        a.entrypoint,
+			WorkingDir:        a.workingDir,
+			User:              a.user,
+			Hostname:          a.hostname,
+			Labels:            a.labels,
+			Memory:            a.memory,
+			CPUs:              a.cpus,
+			CapAdd:            a.capAdd,
+			CapDrop:           a.capDrop,
+			Privileged:        a.privileged,
+			ReadOnly:          a.readOnly,
+			Tmpfs:             a.tmpfs,
+			Devices:           a.devices,
+			ExtraHosts:        a.extraHosts,
+			DNS:               a.dns,
+			LogDriver:         a.logDriver,
+			LogOpts:           a.logOpts,
+			HealthCmd:         a.healthCmd,
+			HealthInterval:    a.healthInterval,
+			HealthTimeout:     a.healthTimeout,
+			HealthRetries:     a.healthRetries,
+			HealthStartPeriod: a.healthStartPeriod,
+			StopSignal:        a.stopSignal,
+			StopTimeout:       a.stopTimeout,
+			AutoRemove:        a.autoRemove,
+		}
+	}
+	t.Escape()
+	return t
+}
+
+// applyTemplate fills the form from a template whose variables have
+// already been substituted. Call it before Init so the form shows the values.
+func (m *ContainerCreateModel) applyTemplate(t *templates.Template) {
+	m.templateName = t.Name
+	m.templateDescription = t.Description
+	m.imageName = t.Image
+	m.containerName = t.ContainerName
+	m.ports = t.Ports
+	m.volumes = t.Volumes
+	m.envVars = t.Environment
+	m.command = t.Command
+	m.networkNames = strings.Fields(t.Network)
+	for name, ep := range t.Endpoints {
+		if ep != nil {
+			*m.endpoint(name) = endpointOptions{
+				aliases: ep.Aliases,
+				ipv4:    ep.IPv4,
+				ipv6:    ep.IPv6,
+				links:   ep.Links,
+			}
+		}
+	}
+	if t.Restart != "" {
+		m.restart = t.Restart
+	}
+
+	if a := t.Advanced; a != nil {
+		m.showAdvanced = true
+		m.advanced = advancedOptions{
+			entrypoint:        a.Entrypoint,
+			workingDir:        a.WorkingDir,
+			user:              a.User,
+			hostname:          a.Hostname,
+			labels:            a.Labels,
+			memory:            a.Memory,
+			cpus:              a.CPUs,
+			capAdd:            a.CapAdd,
+			capDrop:           a.CapDrop,
+			privileged:        a.Privileged,
+			readOnly:          a.ReadOnly,
+			tmpfs:             a.Tmpfs,
+			devices:           a.Devices,
+			extraHosts:        a.ExtraHosts,
+			dns:               a.DNS,
+			logDriver:         a.LogDriver,
+			logOpts:           a.LogOpts,
+			healthCmd:         a.HealthCmd,
+			healthInterval:    a.HealthInterval,
+			healthTimeout:     a.HealthTimeout,
+			healthRetries:     a.HealthRetries,
+			healthStartPeriod: a.HealthStartPeriod,
+			stopSignal:        a.StopSignal,
+			stopTimeout:       a.StopTimeout,
+			autoRemove:        a.AutoRemove,
+		}
+	}
+}
+
+// startSaveTemplate prompts for the name to save the form values under
+func (m *ContainerCreateModel) startSaveTemplate() tea.Cmd {
+	input := textinput.New()
+	input.Placeholder = "Template name, e.g., postgres-dev"
+	input.Width = 40
+	input.PromptStyle = lipgloss.NewStyle().Foreground(ColorPrimary)
+	input.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	m.templateInput = input
+	m.savingTemplate = true
+	m.templateErr = ""
+	m.overwrite = ""
+	return m.templateInput.Focus()
+}
+
+// updateSaveTemplate handles keys while the template name prompt is shown
+func (m *ContainerCreateModel) updateSaveTemplate(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			m.savingTemplate = false
+			return nil
+
+		case "enter":
+			name := m.templateInput.Value()
+			// Saving over a template, for example the one the form was
+			// filled from, would replace its variables with the values used
+			if templates.Exists(name) && m.overwrite != name {
+				m.overwrite = name
+				m.templateErr = fmt.Sprintf("Template %s exists, press enter again to replace it", name)
+				return nil
+			}
+			if err := templates.Save(m.template(name)); err != nil {
+				m.templateErr = err.Error()
+				return nil
+			}
+			m.savingTemplate = false
+			m.notice = fmt.Sprintf("Saved template %s", name)
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.templateInput, cmd = m.templateInput.Update(msg)
+	return cmd
+}
+
+// renderSaveTemplate renders the template name prompt
+func (m *ContainerCreateModel) renderSaveTemplate() string {
+	lines := []string{
+		"Save the form values as a template. Edit the template from the",
+		"template list (T) to replace values with ${VARIABLES}.",
+		"",
+		m.templateInput.View(),
+	}
+	if m.templateErr != "" {
+		lines = append(lines, "", StyleWarning.Render(m.templateErr))
+	}
+
+	return StyleMainLayout.Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Save Template"),
+			StyleInfoBox.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+			"",
+			StyleHelp.Render("Enter: Save • Esc: Back to the form"),
+		),
+	)
+}
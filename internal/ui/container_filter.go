@@ -0,0 +1,352 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// savedFiltersFile stores named container filters in the config directory
+const savedFiltersFile = "container_filters.json"
+
+// filterTerm is a single key/operator/value condition of a filter expression
+type filterTerm struct {
+	key   string
+	op    string // "=" for an exact match, "~" for a substring match
+	value string
+}
+
+// ContainerFilter is a parsed container filter expression such as
+// `state=running label=team=payments image=nginx network=backend name~api`.
+// Terms with different keys must all match; terms sharing a key match if any
+// of them does, like the daemon filters they are translated to.
+type ContainerFilter struct {
+	Expr  string
+	terms []filterTerm
+}
+
+// filterKeys lists the keys accepted in filter expressions
+var filterKeys = []string{"state", "label", "image", "network", "name", "id", "health"}
+
+// ParseContainerFilter parses a filter expression
+func ParseContainerFilter(expr string) (ContainerFilter, error) {
+	f := ContainerFilter{Expr: strings.TrimSpace(expr)}
+
+	for _, token := range strings.Fields(expr) {
+		i := strings.IndexAny(token, "=~")
+		if i <= 0 {
+			return ContainerFilter{}, fmt.Errorf("invalid term %q, expected key=value or key~value", token)
+		}
+
+		term := filterTerm{
+			key:   strings.ToLower(token[:i]),
+			op:    token[i : i+1],
+			value: token[i+1:],
+		}
+		if term.key == "status" {
+			term.key = "state"
+		}
+		if !isFilterKey(term.key) {
+			return ContainerFilter{}, fmt.Errorf("unknown filter key %q, expected one of %s", term.key, strings.Join(filterKeys, ", "))
+		}
+		if term.value == "" {
+			return ContainerFilter{}, fmt.Errorf("missing value in %q", token)
+		}
+		f.terms = append(f.terms, term)
+	}
+
+	return f, nil
+}
+
+// isFilterKey reports whether key is a known filter key
+func isFilterKey(key string) bool {
+	for _, k := range filterKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty reports whether the filter has no terms
+func (f ContainerFilter) IsEmpty() bool {
+	return len(f.terms) == 0
+}
+
+// daemonKey returns the ListOptions filter a term translates to, or an empty
+// string if the term has to be evaluated client side
+func (t filterTerm) daemonKey() string {
+	switch {
+	case t.key == "state" && t.op == "=":
+		return "status"
+	case t.key == "label" && t.op == "=":
+		return "label"
+	case t.key == "image" && t.op == "=":
+		return "ancestor"
+	case t.key == "network" && t.op == "=":
+		return "network"
+	case t.key == "health" && t.op == "=":
+		return "health"
+	case t.key == "id":
+		return "id"
+	case t.key == "name":
+		return "name"
+	}
+	return ""
+}
+
+// Args returns the daemon-side part of the filter
+func (f ContainerFilter) Args() filters.Args {
+	args := filters.NewArgs()
+	for _, t := range f.terms {
+		key := t.daemonKey()
+		switch {
+		case key == "":
+			continue
+		case key == "name" && t.op == "=":
+			// The daemon matches names as regular expressions
+			args.Add(key, "^/?"+regexp.QuoteMeta(t.value)+"$")
+		case key == "name":
+			args.Add(key, regexp.QuoteMeta(t.value))
+		default:
+			args.Add(key, t.value)
+		}
+	}
+	return args
+}
+
+// Match evaluates the terms the daemon cannot filter on
+func (f ContainerFilter) Match(c Summary) bool {
+	groups := map[string][]filterTerm{}
+	for _, t := range f.terms {
+		if t.daemonKey() == "" {
+			groups[t.key] = append(groups[t.key], t)
+		}
+	}
+
+	for _, terms := range groups {
+		matched := false
+		for _, t := range terms {
+			if t.match(c) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// match evaluates a substring term against a container
+func (t filterTerm) match(c Summary) bool {
+	value := strings.ToLower(t.value)
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), value)
+	}
+
+	switch t.key {
+	case "state":
+		return contains(c.State)
+	case "image":
+		return contains(c.Image)
+	case "network":
+		if c.NetworkSettings != nil {
+			for name := range c.NetworkSettings.Networks {
+				if contains(name) {
+					return true
+				}
+			}
+		}
+	case "health":
+		// Match the start of the health so that healthy excludes unhealthy
+		return strings.HasPrefix(healthStatus(c.Status), value)
+	case "label":
+		for k, v := range c.Labels {
+			if contains(k + "=" + v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// healthStatus returns the health in a status such as "Up 5 minutes
+// (healthy)" or "Up 3 seconds (health: starting)", or "" without one
+func healthStatus(status string) string {
+	start := strings.LastIndex(status, "(")
+	end := strings.LastIndex(status, ")")
+	if start < 0 || end < start {
+		return ""
+	}
+	health := strings.ToLower(status[start+1 : end])
+	return strings.TrimPrefix(health, "health: ")
+}
+
+// loadSavedFilters reads the named filters from the config directory
+func loadSavedFilters() map[string]string {
+	saved := map[string]string{}
+	if err := config.LoadJSON(savedFiltersFile, &saved); err != nil {
+		return map[string]string{}
+	}
+	return saved
+}
+
+// savedFilterNames returns the names of the saved filters in order
+func (m *ContainerModel) savedFilterNames() []string {
+	names := make([]string, 0, len(m.savedFilters))
+	for name := range m.savedFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyFilterInput parses the filter bar input, recalling saved filters
+// referenced as @name, and makes it the active filter
+func (m *ContainerModel) applyFilterInput() error {
+	input := strings.TrimSpace(m.filterInput.Value())
+	if strings.HasPrefix(input, "@") {
+		expr, ok := m.savedFilters[strings.TrimPrefix(input, "@")]
+		if !ok {
+			return fmt.Errorf("no saved filter named %q", strings.TrimPrefix(input, "@"))
+		}
+		input = expr
+	}
+
+	f, err := ParseContainerFilter(input)
+	if err != nil {
+		return err
+	}
+	m.filter = f
+	m.filterInput.SetValue(f.Expr)
+	return nil
+}
+
+// saveFilter stores the filter bar expression under a name
+func (m *ContainerModel) saveFilter(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("filter name is required")
+	}
+	if _, err := ParseContainerFilter(m.filterInput.Value()); err != nil {
+		return err
+	}
+	m.savedFilters[name] = strings.TrimSpace(m.filterInput.Value())
+	return config.SaveJSON(savedFiltersFile, m.savedFilters)
+}
+
+// deleteFilter removes a saved filter
+func (m *ContainerModel) deleteFilter(name string) error {
+	if _, ok := m.savedFilters[name]; !ok {
+		return fmt.Errorf("no saved filter named %q", name)
+	}
+	delete(m.savedFilters, name)
+	return config.SaveJSON(savedFiltersFile, m.savedFilters)
+}
+
+// filterSummary describes the active filters for the list title
+func (m *ContainerModel) filterSummary() string {
+	var parts []string
+	if !m.showAll {
+		parts = append(parts, "running only")
+	}
+	if !m.filter.IsEmpty() {
+		parts = append(parts, m.filter.Expr)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// updateFilterBar handles keys while the filter bar is open
+func (m *ContainerModel) updateFilterBar(msg tea.KeyMsg) tea.Cmd {
+	if m.filterSaving {
+		switch msg.String() {
+		case "enter":
+			m.filterErr = m.saveFilter(m.filterNameInput.Value())
+			if m.filterErr == nil {
+				m.filterSaving = false
+				m.filterNameInput.Blur()
+				return m.filterInput.Focus()
+			}
+			return nil
+		case "esc":
+			m.filterSaving = false
+			m.filterNameInput.Blur()
+			return m.filterInput.Focus()
+		}
+		var cmd tea.Cmd
+		m.filterNameInput, cmd = m.filterNameInput.Update(msg)
+		return cmd
+	}
+
+	switch msg.String() {
+	case "enter":
+		m.filterErr = m.applyFilterInput()
+		if m.filterErr != nil {
+			return nil
+		}
+		m.filterInput.Blur()
+		m.state = "list"
+		m.loading = true
+		return tea.Batch(m.fetchContainers(), m.spinner.Tick)
+
+	case "esc":
+		m.filterInput.Blur()
+		m.state = "list"
+		return nil
+
+	case "ctrl+s":
+		m.filterSaving = true
+		m.filterNameInput.Reset()
+		m.filterInput.Blur()
+		return m.filterNameInput.Focus()
+
+	case "ctrl+x":
+		input := strings.TrimSpace(m.filterInput.Value())
+		m.filterErr = m.deleteFilter(strings.TrimPrefix(input, "@"))
+		if m.filterErr == nil {
+			m.filterInput.Reset()
+		}
+		return nil
+
+	case "ctrl+r":
+		m.filterInput.Reset()
+		m.filterErr = nil
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	return cmd
+}
+
+// renderFilterBar renders the filter input, saved filters and errors
+func (m *ContainerModel) renderFilterBar() string {
+	lines := []string{m.filterInput.View()}
+	if m.filterSaving {
+		lines = append(lines, m.filterNameInput.View())
+	}
+	if m.filterErr != nil {
+		lines = append(lines, StyleError.Render(m.filterErr.Error()))
+	}
+
+	lines = append(lines, "", "Keys: "+strings.Join(filterKeys, ", ")+" • = exact, ~ contains")
+	if names := m.savedFilterNames(); len(names) > 0 {
+		lines = append(lines, "", "Saved filters (recall with @name):")
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("  @%s  %s", name, StyleSubtle.Render(m.savedFilters[name])))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleInfoBox.Render(strings.Join(lines, "\n")),
+		StyleFooter.Render("Enter: Apply • ctrl+s: Save • ctrl+x: Delete @name • ctrl+r: Clear • Esc: Cancel"),
+	)
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// composeProjectLabel is the label Docker Compose puts on project containers
+const composeProjectLabel = "com.docker.compose.project"
+
+// ContainerGroupItem is a group heading in the grouped container list
+type ContainerGroupItem struct {
+	name       string
+	label      string
+	containers []Summary
+	collapsed  bool
+}
+
+// FilterValue implements list.Item interface
+func (i ContainerGroupItem) FilterValue() string { return i.name }
+
+// Title returns the title for the list item
+func (i ContainerGroupItem) Title() string {
+	arrow := "▾"
+	if i.collapsed {
+		arrow = "▸"
+	}
+	running, stopped := countStates(i.containers)
+
+	title := fmt.Sprintf("%s %s  %s", arrow, i.name, StyleSuccess.Render(fmt.Sprintf("%d running", running)))
+	if stopped > 0 {
+		title += " " + StyleWarning.Render(fmt.Sprintf("%d stopped", stopped))
+	}
+	return title
+}
+
+// Description returns the description for the list item
+func (i ContainerGroupItem) Description() string {
+	return fmt.Sprintf("%d containers • %s", len(i.containers), i.label)
+}
+
+// countStates counts running and not running containers
+func countStates(containers []Summary) (running, stopped int) {
+	for _, c := range containers {
+		if c.State == "running" {
+			running++
+		} else {
+			stopped++
+		}
+	}
+	return running, stopped
+}
+
+// groupContainers groups containers by the value of a label. Containers
+// without the label are collected in a trailing group.
+func groupContainers(containers []Summary, label string) []ContainerGroupItem {
+	byName := map[string][]Summary{}
+	var ungrouped []Summary
+	for _, c := range containers {
+		if value, ok := c.Labels[label]; ok && value != "" {
+			byName[value] = append(byName[value], c)
+		} else {
+			ungrouped = append(ungrouped, c)
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	groups := make([]ContainerGroupItem, 0, len(names)+1)
+	for _, name := range names {
+		groups = append(groups, ContainerGroupItem{name: name, label: label, containers: byName[name]})
+	}
+	if len(ungrouped) > 0 {
+		groups = append(groups, ContainerGroupItem{
+			name:       fmt.Sprintf("(no %s)", label),
+			label:      label,
+			containers: ungrouped,
+		})
+	}
+	return groups
+}
+
+// groupedItems builds list items with a heading per group
+func (m *ContainerModel) groupedItems() []list.Item {
+	var items []list.Item
+	for _, group := range groupContainers(m.containers, m.groupLabel) {
+		group.collapsed = m.collapsed[group.name]
+		items = append(items, group)
+		if group.collapsed {
+			continue
+		}
+		for _, c := range group.containers {
+			items = append(items, m.newContainerItem(c))
+		}
+	}
+	return items
+}
+
+// selectedGroup returns the group heading under the cursor
+func (m *ContainerModel) selectedGroup() (ContainerGroupItem, bool) {
+	if m.tableMode || m.groupLabel == "" {
+		return ContainerGroupItem{}, false
+	}
+	group, ok := m.containerList.SelectedItem().(ContainerGroupItem)
+	return group, ok
+}
+
+// toggleGrouping switches grouping by the current group label on or off
+func (m *ContainerModel) toggleGrouping() tea.Cmd {
+	if m.groupLabel != "" {
+		m.groupLabel = ""
+		// Back to the saved layout, fetching sizes if it shows them
+		hadSizes := m.sizeColumnVisible()
+		m.tableMode = m.layout.Enabled
+		if !hadSizes && m.sizeColumnVisible() {
+			m.loading = true
+			return tea.Batch(m.refreshItems(), m.fetchContainers(), m.spinner.Tick)
+		}
+	} else {
+		m.groupLabel = m.groupLabelInput.Value()
+		if m.groupLabel == "" {
+			m.groupLabel = composeProjectLabel
+		}
+		// Groups are rendered as list headings. The saved layout is left
+		// alone so the table comes back once grouping is off.
+		m.tableMode = false
+	}
+	return m.refreshItems()
+}
+
+// toggleCollapsed folds or unfolds the group under the cursor
+func (m *ContainerModel) toggleCollapsed(group ContainerGroupItem) tea.Cmd {
+	if m.collapsed[group.name] {
+		delete(m.collapsed, group.name)
+	} else {
+		m.collapsed[group.name] = true
+	}
+	return m.refreshItems()
+}
+
+// updateGroupLabel handles keys while the group label prompt is open
+func (m *ContainerModel) updateGroupLabel(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		m.groupLabel = strings.TrimSpace(m.groupLabelInput.Value())
+		if m.groupLabel == "" {
+			m.groupLabel = composeProjectLabel
+			m.groupLabelInput.SetValue(composeProjectLabel)
+		}
+		m.collapsed = map[string]bool{}
+		m.tableMode = false
+		m.groupLabelInput.Blur()
+		m.state = "list"
+		return m.refreshItems()
+
+	case "esc":
+		m.groupLabelInput.Blur()
+		m.state = "list"
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.groupLabelInput, cmd = m.groupLabelInput.Update(msg)
+	return cmd
+}
@@ -0,0 +1,359 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/shellwords"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// runFlag describes a docker run flag and how it maps onto the create form
+type runFlag struct {
+	takesValue bool
+	// advanced marks flags that fill the advanced form groups
+	advanced bool
+	// apply stores the value in the form, nil for flags the form cannot hold
+	apply func(m *ContainerCreateModel, value string) error
+}
+
+// setField returns an apply func that stores the value in a form field
+func setField(field func(m *ContainerCreateModel) *string) func(*ContainerCreateModel, string) error {
+	return func(m *ContainerCreateModel, value string) error {
+		*field(m) = value
+		return nil
+	}
+}
+
+// appendField returns an apply func that appends the shell-quoted value to
+// a space-separated form field
+func appendField(field func(m *ContainerCreateModel) *string) func(*ContainerCreateModel, string) error {
+	return func(m *ContainerCreateModel, value string) error {
+		dst := field(m)
+		if *dst != "" {
+			*dst += " "
+		}
+		*dst += shellwords.Quote(value)
+		return nil
+	}
+}
+
+// setBool returns an apply func for a boolean flag
+func setBool(field func(m *ContainerCreateModel) *bool) func(*ContainerCreateModel, string) error {
+	return func(m *ContainerCreateModel, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(m) = b
+		return nil
+	}
+}
+
+// addNetwork attaches the container to one more network
+func addNetwork(m *ContainerCreateModel, value string) error {
+	if !slices.Contains(m.networkNames, value) {
+		m.networkNames = append(m.networkNames, value)
+	}
+	return nil
+}
+
+// runFlags maps long docker run flag names onto the create form
+var runFlags = map[string]runFlag{
+	"name":    {takesValue: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.containerName })},
+	"publish": {takesValue: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.ports })},
+	"volume":  {takesValue: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.volumes })},
+	"env":     {takesValue: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.envVars })},
+	"network": {takesValue: true, apply: addNetwork},
+	"net":     {takesValue: true, apply: addNetwork},
+	// Endpoint flags apply to the first network and are moved there once
+	// all flags are parsed
+	"network-alias": {takesValue: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.endpoint("").aliases })},
+	"ip":            {takesValue: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.endpoint("").ipv4 })},
+	"ip6":           {takesValue: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.endpoint("").ipv6 })},
+	"link":          {takesValue: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.endpoint("").links })},
+	"restart": {takesValue: true, apply: func(m *ContainerCreateModel, value string) error {
+		switch value {
+		case "no", "always", "on-failure", "unless-stopped":
+			m.restart = value
+			return nil
+		}
+		return fmt.Errorf("restart policy %q is not offered by the form", value)
+	}},
+	"detach": {apply: func(*ContainerCreateModel, string) error { return nil }},
+
+	"entrypoint": {takesValue: true, advanced: true, apply: func(m *ContainerCreateModel, value string) error {
+		m.advanced.entrypoint = shellwords.Quote(value)
+		return nil
+	}},
+	"workdir":             {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.workingDir })},
+	"user":                {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.user })},
+	"hostname":            {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.hostname })},
+	"label":               {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.labels })},
+	"memory":              {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.memory })},
+	"cpus":                {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.cpus })},
+	"cap-add":             {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.capAdd })},
+	"cap-drop":            {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.capDrop })},
+	"privileged":          {advanced: true, apply: setBool(func(m *ContainerCreateModel) *bool { return &m.advanced.privileged })},
+	"read-only":           {advanced: true, apply: setBool(func(m *ContainerCreateModel) *bool { return &m.advanced.readOnly })},
+	"rm":                  {advanced: true, apply: setBool(func(m *ContainerCreateModel) *bool { return &m.advanced.autoRemove })},
+	"tmpfs":               {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.tmpfs })},
+	"device":              {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.devices })},
+	"add-host":            {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.extraHosts })},
+	"dns":                 {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.dns })},
+	"log-driver":          {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.logDriver })},
+	"log-opt":             {takesValue: true, advanced: true, apply: appendField(func(m *ContainerCreateModel) *string { return &m.advanced.logOpts })},
+	"health-cmd":          {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.healthCmd })},
+	"health-interval":     {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.healthInterval })},
+	"health-timeout":      {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.healthTimeout })},
+	"health-retries":      {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.healthRetries })},
+	"health-start-period": {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.healthStartPeriod })},
+	"stop-signal":         {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.stopSignal })},
+	"stop-timeout":        {takesValue: true, advanced: true, apply: setField(func(m *ContainerCreateModel) *string { return &m.advanced.stopTimeout })},
+
+	// Boolean flags the form has no field for
+	"interactive":           {},
+	"tty":                   {},
+	"publish-all":           {},
+	"init":                  {},
+	"oom-kill-disable":      {},
+	"no-healthcheck":        {},
+	"sig-proxy":             {},
+	"disable-content-trust": {},
+	"quiet":                 {},
+	"help":                  {},
+}
+
+// runShortFlags maps single-letter docker run flags to their long names
+var runShortFlags = map[byte]string{
+	'd': "detach",
+	'e': "env",
+	'h': "hostname",
+	'i': "interactive",
+	'l': "label",
+	'm': "memory",
+	'p': "publish",
+	'P': "publish-all",
+	'q': "quiet",
+	't': "tty",
+	'u': "user",
+	'v': "volume",
+	'w': "workdir",
+}
+
+// importDockerRun parses a docker run command line into the form fields,
+// replacing their values. Flags the form cannot represent are returned so
+// they can be reviewed. A command that fails to parse leaves the form as it
+// was.
+func (m *ContainerCreateModel) importDockerRun(line string) ([]string, error) {
+	draft := &ContainerCreateModel{restart: "no"}
+	unmapped, err := draft.parseDockerRun(line)
+	if err != nil {
+		return unmapped, err
+	}
+
+	m.imageName = draft.imageName
+	m.containerName = draft.containerName
+	m.ports = draft.ports
+	m.volumes = draft.volumes
+	m.envVars = draft.envVars
+	m.command = draft.command
+	m.networkNames = draft.networkNames
+	m.endpoints = draft.endpoints
+	m.restart = draft.restart
+	m.showAdvanced = draft.showAdvanced
+	m.advanced = draft.advanced
+	return unmapped, nil
+}
+
+// parseDockerRun parses a docker run command line into the form values of a
+// model holding no other values
+func (m *ContainerCreateModel) parseDockerRun(line string) ([]string, error) {
+	args, err := shellwords.Split(line)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accept the command with or without the docker prefix
+	if len(args) > 0 && args[0] == "docker" {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "container" {
+		args = args[1:]
+	}
+	if len(args) > 0 && (args[0] == "run" || args[0] == "create") {
+		args = args[1:]
+	}
+
+	var unmapped []string
+	set := func(name, value string, hasValue bool, display string) error {
+		flag, known := runFlags[name]
+		if !known || flag.apply == nil {
+			unmapped = append(unmapped, display)
+			return nil
+		}
+		if !flag.takesValue && !hasValue {
+			value = "true"
+		}
+		if err := flag.apply(m, value); err != nil {
+			unmapped = append(unmapped, fmt.Sprintf("%s (%v)", display, err))
+			return nil
+		}
+		if flag.advanced {
+			m.showAdvanced = true
+		}
+		return nil
+	}
+
+	// takesValue reports whether an unknown flag consumes the next argument.
+	// Unknown flags are assumed to take one unless written as --flag=value.
+	takesValue := func(name string) bool {
+		flag, known := runFlags[name]
+		return !known || flag.takesValue
+	}
+
+	i := 0
+	for ; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			i++
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			break
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			if !hasValue && takesValue(name) {
+				if i+1 >= len(args) {
+					return unmapped, fmt.Errorf("flag --%s needs a value", name)
+				}
+				i++
+				value, hasValue = args[i], true
+			}
+			display := "--" + name
+			if hasValue {
+				display += " " + shellwords.Quote(value)
+			}
+			if err := set(name, value, hasValue, display); err != nil {
+				return unmapped, err
+			}
+			continue
+		}
+
+		// Short flags may be combined (-it) and may carry their value (-p80:80)
+		for j := 1; j < len(arg); j++ {
+			name, ok := runShortFlags[arg[j]]
+			if !ok {
+				name = string(arg[j])
+			}
+			if !takesValue(name) {
+				if err := set(name, "", false, "-"+string(arg[j])); err != nil {
+					return unmapped, err
+				}
+				continue
+			}
+
+			value := arg[j+1:]
+			if value == "" {
+				if i+1 >= len(args) {
+					return unmapped, fmt.Errorf("flag -%c needs a value", arg[j])
+				}
+				i++
+				value = args[i]
+			}
+			if err := set(name, value, true, fmt.Sprintf("-%c %s", arg[j], shellwords.Quote(value))); err != nil {
+				return unmapped, err
+			}
+			break
+		}
+	}
+
+	// Without --network the container goes on the default bridge
+	if len(m.networkNames) == 0 {
+		m.networkNames = []string{"bridge"}
+	}
+	if pending, ok := m.endpoints[""]; ok {
+		delete(m.endpoints, "")
+		m.endpoints[m.networkNames[0]] = pending
+	}
+
+	if i >= len(args) {
+		return unmapped, fmt.Errorf("no image given")
+	}
+	m.imageName = args[i]
+	m.command = shellwords.Join(args[i+1:])
+
+	return unmapped, nil
+}
+
+// StartImport switches the create model to the docker run import prompt
+func (m *ContainerCreateModel) StartImport() tea.Cmd {
+	input := textarea.New()
+	input.Placeholder = "docker run -d --name web -p 8080:80 nginx"
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.SetWidth(m.width - 8)
+	input.SetHeight(6)
+
+	m.importInput = input
+	m.importing = true
+	m.importErr = ""
+	return m.importInput.Focus()
+}
+
+// updateImport handles messages while the import prompt is shown
+func (m *ContainerCreateModel) updateImport(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "ctrl+s" {
+		unmapped, err := m.importDockerRun(m.importInput.Value())
+		if err != nil {
+			m.importErr = err.Error()
+			return nil
+		}
+
+		m.unmapped = unmapped
+		m.importing = false
+		m.importInput.Blur()
+		m.initForm()
+		return m.form.Init()
+	}
+
+	var cmd tea.Cmd
+	m.importInput, cmd = m.importInput.Update(msg)
+	return cmd
+}
+
+// renderImport renders the docker run import prompt
+func (m *ContainerCreateModel) renderImport() string {
+	lines := []string{
+		"Paste a docker run command. Line continuations and quotes are handled like in a shell.",
+		"",
+		m.importInput.View(),
+	}
+	if m.importErr != "" {
+		lines = append(lines, "", StyleError.Render(m.importErr))
+	}
+
+	return StyleMainLayout.Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Import docker run"),
+			StyleInfoBox.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+			"",
+			StyleHelp.Render("ctrl+s: Import and review • Esc: Back"),
+		),
+	)
+}
+
+// renderUnmapped lists the imported flags the form could not represent
+func (m *ContainerCreateModel) renderUnmapped() string {
+	lines := []string{StyleWarning.Render("These flags were not imported and will not be applied:")}
+	for _, flag := range m.unmapped {
+		lines = append(lines, StyleWarning.Render("  "+flag))
+	}
+	return StyleInfoBox.BorderForeground(ColorWarning).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
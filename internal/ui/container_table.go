@@ -0,0 +1,485 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/internal/config"
+	"github.com/Gostatsog/dockerNav/pkg/formatter"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tableLayoutFile stores the container table layout in the config directory
+const tableLayoutFile = "container_table.json"
+
+// minColumnWidth is the narrowest a table column can be resized to
+const minColumnWidth = 4
+
+// containerColumn describes a column of the container table
+type containerColumn struct {
+	key   string
+	title string
+	width int
+	value func(c Summary) string
+	less  func(a, b Summary) bool
+}
+
+// containerColumns lists every column the container table can show
+var containerColumns = []containerColumn{
+	{key: "name", title: "NAME", width: 24, value: containerName},
+	{key: "image", title: "IMAGE", width: 28, value: func(c Summary) string { return c.Image }},
+	{key: "state", title: "STATE", width: 10, value: func(c Summary) string { return c.State }},
+	{key: "status", title: "STATUS", width: 22, value: func(c Summary) string { return c.Status }},
+	{key: "ports", title: "PORTS", width: 28, value: formatContainerPorts},
+	{
+		key: "created", title: "CREATED", width: 16,
+		value: func(c Summary) string { return formatter.FormatTime(time.Unix(c.Created, 0)) },
+		less:  func(a, b Summary) bool { return a.Created < b.Created },
+	},
+	{
+		key: "size", title: "SIZE", width: 24,
+		value: func(c Summary) string {
+			return fmt.Sprintf("%s (virtual %s)",
+				formatter.FormatSize(float64(c.SizeRw)),
+				formatter.FormatSize(float64(c.SizeRootFs)),
+			)
+		},
+		less: func(a, b Summary) bool { return a.SizeRw < b.SizeRw },
+	},
+	{key: "networks", title: "NETWORKS", width: 20, value: formatContainerNetworks},
+	{key: "ips", title: "IPS", width: 18, value: formatContainerIPs},
+	{key: "labels", title: "LABELS", width: 30, value: formatContainerLabels},
+}
+
+// ColumnLayout is the persisted state of one table column
+type ColumnLayout struct {
+	Key     string `json:"key"`
+	Visible bool   `json:"visible"`
+	Width   int    `json:"width"`
+}
+
+// TableLayout is the persisted layout of the container table
+type TableLayout struct {
+	Enabled  bool           `json:"enabled"`
+	Columns  []ColumnLayout `json:"columns"`
+	SortKey  string         `json:"sortKey"`
+	SortDesc bool           `json:"sortDesc"`
+}
+
+// ContainerTableKeyMap defines keybindings specific to the table view
+type ContainerTableKeyMap struct {
+	Toggle     key.Binding
+	PrevColumn key.Binding
+	NextColumn key.Binding
+	Sort       key.Binding
+	Grow       key.Binding
+	Shrink     key.Binding
+	Columns    key.Binding
+}
+
+// DefaultContainerTableKeyMap returns default table keybindings
+func DefaultContainerTableKeyMap() ContainerTableKeyMap {
+	return ContainerTableKeyMap{
+		Toggle: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "list/table"),
+		),
+		PrevColumn: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "previous column"),
+		),
+		NextColumn: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "next column"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "sort"),
+		),
+		Grow: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "widen column"),
+		),
+		Shrink: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "narrow column"),
+		),
+		Columns: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "choose columns"),
+		),
+	}
+}
+
+// newContainerTable creates the table widget used by the table view
+func newContainerTable() table.Model {
+	keyMap := table.DefaultKeyMap()
+	// Space and u/d are used by container actions
+	keyMap.PageDown = key.NewBinding(key.WithKeys("f", "pgdown"))
+	keyMap.HalfPageUp = key.NewBinding(key.WithKeys("ctrl+u"))
+	keyMap.HalfPageDown = key.NewBinding(key.WithKeys("ctrl+d"))
+
+	styles := table.DefaultStyles()
+	styles.Header = StyleTableHeader.Padding(0, 1)
+	styles.Selected = StyleSelected
+
+	return table.New(
+		table.WithKeyMap(keyMap),
+		table.WithStyles(styles),
+		table.WithFocused(true),
+	)
+}
+
+// defaultTableLayout returns the layout used before any customisation
+func defaultTableLayout() TableLayout {
+	layout := TableLayout{SortKey: "name"}
+	for _, col := range containerColumns {
+		visible := false
+		switch col.key {
+		case "name", "image", "state", "status", "ports":
+			visible = true
+		}
+		layout.Columns = append(layout.Columns, ColumnLayout{
+			Key:     col.key,
+			Visible: visible,
+			Width:   col.width,
+		})
+	}
+	return layout
+}
+
+// loadTableLayout reads the persisted layout, keeping it in sync with the
+// known columns
+func loadTableLayout() TableLayout {
+	layout := defaultTableLayout()
+	var saved TableLayout
+	if err := config.LoadJSON(tableLayoutFile, &saved); err != nil || len(saved.Columns) == 0 {
+		return layout
+	}
+
+	known := map[string]bool{}
+	for _, col := range containerColumns {
+		known[col.key] = true
+	}
+
+	merged := TableLayout{Enabled: saved.Enabled, SortKey: saved.SortKey, SortDesc: saved.SortDesc}
+	seen := map[string]bool{}
+	for _, col := range saved.Columns {
+		if !known[col.Key] || seen[col.Key] {
+			continue
+		}
+		if col.Width < minColumnWidth {
+			col.Width = minColumnWidth
+		}
+		seen[col.Key] = true
+		merged.Columns = append(merged.Columns, col)
+	}
+	// Columns added after the layout was saved are appended hidden
+	for _, col := range layout.Columns {
+		if !seen[col.Key] {
+			col.Visible = false
+			merged.Columns = append(merged.Columns, col)
+		}
+	}
+	if !known[merged.SortKey] {
+		merged.SortKey = "name"
+	}
+	return merged
+}
+
+// lookupColumn returns the column definition for a key
+func lookupColumn(key string) containerColumn {
+	for _, col := range containerColumns {
+		if col.key == key {
+			return col
+		}
+	}
+	return containerColumns[0]
+}
+
+// visibleColumns returns the layouts of the columns currently shown
+func (m *ContainerModel) visibleColumns() []ColumnLayout {
+	var visible []ColumnLayout
+	for _, col := range m.layout.Columns {
+		if col.Visible {
+			visible = append(visible, col)
+		}
+	}
+	return visible
+}
+
+// sizeColumnVisible reports whether container sizes must be requested
+func (m *ContainerModel) sizeColumnVisible() bool {
+	if !m.tableMode {
+		return false
+	}
+	for _, col := range m.visibleColumns() {
+		if col.Key == "size" {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedContainers returns the containers ordered by the table sort column
+func (m *ContainerModel) sortedContainers() []Summary {
+	sorted := make([]Summary, len(m.containers))
+	copy(sorted, m.containers)
+
+	col := lookupColumn(m.layout.SortKey)
+	less := col.less
+	if less == nil {
+		less = func(a, b Summary) bool {
+			return strings.ToLower(col.value(a)) < strings.ToLower(col.value(b))
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if m.layout.SortDesc {
+			return less(sorted[j], sorted[i])
+		}
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// refreshTable rebuilds the table columns and rows from the current layout
+func (m *ContainerModel) refreshTable() {
+	visible := m.visibleColumns()
+	if m.columnFocus >= len(visible) {
+		m.columnFocus = len(visible) - 1
+	}
+	if m.columnFocus < 0 {
+		m.columnFocus = 0
+	}
+
+	columns := []table.Column{{Title: " ", Width: 1}}
+	for i, layout := range visible {
+		title := lookupColumn(layout.Key).title
+		if layout.Key == m.layout.SortKey {
+			if m.layout.SortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		if i == m.columnFocus {
+			title = "›" + title
+		}
+		columns = append(columns, table.Column{Title: title, Width: layout.Width})
+	}
+
+	m.tableRows = m.sortedContainers()
+	rows := make([]table.Row, 0, len(m.tableRows))
+	for _, c := range m.tableRows {
+		mark := " "
+		if m.marked[c.ID] {
+			mark = "●"
+		}
+		row := table.Row{mark}
+		for _, layout := range visible {
+			row = append(row, lookupColumn(layout.Key).value(c))
+		}
+		rows = append(rows, row)
+	}
+
+	// Columns must be replaced before rows so row cells match the header
+	m.table.SetRows(nil)
+	m.table.SetColumns(columns)
+	m.table.SetRows(rows)
+	if m.table.Cursor() >= len(rows) && len(rows) > 0 {
+		m.table.SetCursor(len(rows) - 1)
+	}
+}
+
+// handleTableKey handles the column keys of the table view and reports
+// whether the key was consumed
+func (m *ContainerModel) handleTableKey(msg tea.KeyMsg) (bool, error) {
+	switch {
+	case key.Matches(msg, m.tableKeys.PrevColumn):
+		m.moveColumnFocus(-1)
+	case key.Matches(msg, m.tableKeys.NextColumn):
+		m.moveColumnFocus(1)
+	case key.Matches(msg, m.tableKeys.Sort):
+		return true, m.sortByFocused()
+	case key.Matches(msg, m.tableKeys.Grow):
+		return true, m.resizeFocused(2)
+	case key.Matches(msg, m.tableKeys.Shrink):
+		return true, m.resizeFocused(-2)
+	case key.Matches(msg, m.tableKeys.Columns):
+		m.columnCursor = 0
+		m.state = "columns"
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// moveColumnFocus moves the focused column by delta
+func (m *ContainerModel) moveColumnFocus(delta int) {
+	visible := m.visibleColumns()
+	if len(visible) == 0 {
+		return
+	}
+	m.columnFocus = (m.columnFocus + delta + len(visible)) % len(visible)
+	m.refreshTable()
+}
+
+// focusedLayout returns the layout entry of the focused column
+func (m *ContainerModel) focusedLayout() *ColumnLayout {
+	visible := m.visibleColumns()
+	if m.columnFocus >= len(visible) {
+		return nil
+	}
+	for i := range m.layout.Columns {
+		if m.layout.Columns[i].Key == visible[m.columnFocus].Key {
+			return &m.layout.Columns[i]
+		}
+	}
+	return nil
+}
+
+// sortByFocused sorts by the focused column, flipping direction on repeat
+func (m *ContainerModel) sortByFocused() error {
+	col := m.focusedLayout()
+	if col == nil {
+		return nil
+	}
+	if m.layout.SortKey == col.Key {
+		m.layout.SortDesc = !m.layout.SortDesc
+	} else {
+		m.layout.SortKey = col.Key
+		m.layout.SortDesc = false
+	}
+	m.refreshTable()
+	return config.SaveJSON(tableLayoutFile, m.layout)
+}
+
+// setTableMode switches between the table and the list and remembers the
+// choice
+func (m *ContainerModel) setTableMode(enabled bool) error {
+	m.tableMode = enabled
+	m.layout.Enabled = enabled
+	return config.SaveJSON(tableLayoutFile, m.layout)
+}
+
+// resizeFocused changes the width of the focused column by delta
+func (m *ContainerModel) resizeFocused(delta int) error {
+	col := m.focusedLayout()
+	if col == nil {
+		return nil
+	}
+	col.Width += delta
+	if col.Width < minColumnWidth {
+		col.Width = minColumnWidth
+	}
+	m.refreshTable()
+	return config.SaveJSON(tableLayoutFile, m.layout)
+}
+
+// toggleColumn shows or hides the column under the chooser cursor
+func (m *ContainerModel) toggleColumn() error {
+	col := &m.layout.Columns[m.columnCursor]
+	if col.Visible && len(m.visibleColumns()) == 1 {
+		// Keep at least one column on screen
+		return nil
+	}
+	col.Visible = !col.Visible
+	m.refreshTable()
+	return config.SaveJSON(tableLayoutFile, m.layout)
+}
+
+// moveColumn moves the column under the chooser cursor by delta
+func (m *ContainerModel) moveColumn(delta int) error {
+	target := m.columnCursor + delta
+	if target < 0 || target >= len(m.layout.Columns) {
+		return nil
+	}
+	cols := m.layout.Columns
+	cols[m.columnCursor], cols[target] = cols[target], cols[m.columnCursor]
+	m.columnCursor = target
+	m.refreshTable()
+	return config.SaveJSON(tableLayoutFile, m.layout)
+}
+
+// renderColumnChooser renders the column visibility and order editor
+func (m *ContainerModel) renderColumnChooser() string {
+	rows := make([]string, 0, len(m.layout.Columns))
+	for i, layout := range m.layout.Columns {
+		check := "[ ]"
+		if layout.Visible {
+			check = "[x]"
+		}
+		row := fmt.Sprintf("%s %-10s width %d", check, lookupColumn(layout.Key).title, layout.Width)
+		if i == m.columnCursor {
+			row = StyleSelected.Render(row)
+		}
+		rows = append(rows, row)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleInfoBox.Render(strings.Join(rows, "\n")),
+		StyleFooter.Render("↑/↓: Move • space: Show/hide • K/J: Reorder • esc: Done"),
+	)
+}
+
+// formatContainerPorts renders published ports like the docker CLI
+func formatContainerPorts(c Summary) string {
+	ports := make([]string, 0, len(c.Ports))
+	for _, p := range c.Ports {
+		if p.PublicPort == 0 {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type))
+			continue
+		}
+		ports = append(ports, fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type))
+	}
+	return strings.Join(ports, ", ")
+}
+
+// formatContainerNetworks lists the networks a container is attached to
+func formatContainerNetworks(c Summary) string {
+	if c.NetworkSettings == nil {
+		return ""
+	}
+	names := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name := range c.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// formatContainerIPs lists the addresses of a container on all its networks
+func formatContainerIPs(c Summary) string {
+	if c.NetworkSettings == nil {
+		return ""
+	}
+	names := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name := range c.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ips := []string{}
+	for _, name := range names {
+		if ep := c.NetworkSettings.Networks[name]; ep != nil && ep.IPAddress != "" {
+			ips = append(ips, ep.IPAddress)
+		}
+	}
+	return strings.Join(ips, ", ")
+}
+
+// formatContainerLabels renders labels as sorted key=value pairs
+func formatContainerLabels(c Summary) string {
+	labels := make([]string, 0, len(c.Labels))
+	for k, v := range c.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,449 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/templates"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TemplateListMsg carries the saved container templates
+type TemplateListMsg struct {
+	Templates []templates.Template
+	Error     error
+}
+
+// TemplateUseMsg asks the container view to open the create form filled
+// from a template whose variables have been substituted
+type TemplateUseMsg struct {
+	Template *templates.Template
+}
+
+// newTemplateYAML is the starting point for a template created in the editor
+const newTemplateYAML = `name: postgres-dev
+description: Postgres for local development
+image: postgres:16
+container_name: ${NAME:-postgres}-dev
+ports: ${PORT:-5432}:5432
+volumes: ${NAME:-postgres}-data:/var/lib/postgresql/data
+environment: POSTGRES_PASSWORD=${PASSWORD:-postgres}
+restart: unless-stopped
+`
+
+// TemplateItem represents a template in the list
+type TemplateItem struct {
+	template templates.Template
+}
+
+// Title returns the template name
+func (i TemplateItem) Title() string { return i.template.Name }
+
+// Description returns the image, description and variables of the template
+func (i TemplateItem) Description() string {
+	parts := []string{i.template.Image}
+	if i.template.Description != "" {
+		parts = append(parts, i.template.Description)
+	}
+	if vars := i.template.Variables(); len(vars) > 0 {
+		names := make([]string, len(vars))
+		for j, v := range vars {
+			names[j] = v.Name
+		}
+		parts = append(parts, "vars: "+strings.Join(names, ", "))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// FilterValue returns the value used for filtering
+func (i TemplateItem) FilterValue() string { return i.template.Name }
+
+// TemplateKeyMap defines keybindings for the template list
+type TemplateKeyMap struct {
+	Use     key.Binding
+	Edit    key.Binding
+	New     key.Binding
+	Delete  key.Binding
+	Refresh key.Binding
+	Back    key.Binding
+}
+
+// DefaultTemplateKeyMap returns default template keybindings
+func DefaultTemplateKeyMap() TemplateKeyMap {
+	return TemplateKeyMap{
+		Use: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "create from template"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit"),
+		),
+		New: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "new"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "delete"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refresh"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// TemplateModel lists, edits and applies saved container templates
+type TemplateModel struct {
+	list   list.Model
+	keyMap TemplateKeyMap
+	state  string // "list", "variables", "edit", "confirm"
+	width  int
+	height int
+
+	selected  *templates.Template
+	variables []templates.Variable
+	values    []string
+	varForm   *huh.Form
+
+	editor        textarea.Model
+	editName      string // name of the template being edited, empty for a new one
+	editErr       string
+	editOverwrite string // existing template the user agreed to replace
+
+	notice string
+	error  error
+}
+
+// NewTemplateModel creates a new template model
+func NewTemplateModel() *TemplateModel {
+	keyMap := DefaultTemplateKeyMap()
+
+	templateList := list.New([]list.Item{}, newProjectDelegate(), 0, 0)
+	templateList.Title = "Container Templates"
+	templateList.Styles.Title = StyleTitle
+	templateList.SetShowStatusBar(true)
+	templateList.SetFilteringEnabled(true)
+	templateList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{keyMap.Use, keyMap.Edit, keyMap.New, keyMap.Delete, keyMap.Refresh, keyMap.Back}
+	}
+	templateList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keyMap.Use, keyMap.Edit, keyMap.New, keyMap.Delete}
+	}
+
+	return &TemplateModel{
+		list:   templateList,
+		keyMap: keyMap,
+		state:  "list",
+	}
+}
+
+// Init loads the templates
+func (m *TemplateModel) Init() tea.Cmd {
+	return fetchTemplates
+}
+
+// fetchTemplates reads the saved templates
+func fetchTemplates() tea.Msg {
+	list, err := templates.List()
+	return TemplateListMsg{Templates: list, Error: err}
+}
+
+// setSize updates component sizes
+func (m *TemplateModel) setSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	listHeight := height - 8
+	if listHeight < 1 {
+		listHeight = 10
+	}
+	m.list.SetSize(width-4, listHeight)
+	if m.state == "edit" {
+		m.editor.SetWidth(width - 8)
+		m.editor.SetHeight(height - 12)
+	}
+}
+
+// atTop reports whether esc should leave the template view
+func (m *TemplateModel) atTop() bool {
+	return m.state == "list" && m.list.FilterState() == list.Unfiltered
+}
+
+// CapturingInput reports whether a text input currently has focus
+func (m *TemplateModel) CapturingInput() bool {
+	switch m.state {
+	case "variables", "edit":
+		return true
+	case "list":
+		return m.list.SettingFilter()
+	}
+	return false
+}
+
+// selectedTemplate returns the highlighted template
+func (m *TemplateModel) selectedTemplate() *templates.Template {
+	item, ok := m.list.SelectedItem().(TemplateItem)
+	if !ok {
+		return nil
+	}
+	t := item.template
+	return &t
+}
+
+// Update handles messages and updates the model
+func (m *TemplateModel) Update(msg tea.Msg) (*TemplateModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TemplateListMsg:
+		m.error = msg.Error
+		items := make([]list.Item, len(msg.Templates))
+		for i, t := range msg.Templates {
+			items[i] = TemplateItem{template: t}
+		}
+		return m, m.list.SetItems(items)
+
+	case tea.KeyMsg:
+		switch m.state {
+		case "list":
+			if m.list.SettingFilter() {
+				break
+			}
+			switch {
+			case key.Matches(msg, m.keyMap.Use):
+				if t := m.selectedTemplate(); t != nil {
+					return m, m.use(t)
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.Edit):
+				if t := m.selectedTemplate(); t != nil {
+					data, err := templates.Read(t.Name)
+					if err != nil {
+						m.error = err
+						return m, nil
+					}
+					return m, m.openEditor(t.Name, string(data))
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.New):
+				return m, m.openEditor("", newTemplateYAML)
+
+			case key.Matches(msg, m.keyMap.Delete):
+				if t := m.selectedTemplate(); t != nil {
+					m.selected = t
+					m.state = "confirm"
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.Refresh):
+				m.notice = ""
+				return m, fetchTemplates
+			}
+
+		case "confirm":
+			switch msg.String() {
+			case "y", "Y":
+				m.state = "list"
+				if err := templates.Delete(m.selected.Name); err != nil {
+					m.error = err
+					return m, nil
+				}
+				m.notice = fmt.Sprintf("Deleted template %s", m.selected.Name)
+				return m, fetchTemplates
+			case "n", "N", "esc":
+				m.state = "list"
+			}
+			return m, nil
+
+		case "edit":
+			switch msg.String() {
+			case "esc":
+				m.state = "list"
+				return m, nil
+			case "ctrl+s":
+				data := []byte(m.editor.Value())
+				// A new name must not silently replace another template
+				if t, err := templates.Parse(data); err == nil && t.Name != m.editName &&
+					templates.Exists(t.Name) && m.editOverwrite != t.Name {
+					m.editOverwrite = t.Name
+					m.editErr = fmt.Sprintf("Template %s exists, press ctrl+s again to replace it", t.Name)
+					return m, nil
+				}
+				t, err := templates.Write(m.editName, data)
+				if err != nil {
+					m.editErr = err.Error()
+					return m, nil
+				}
+				m.state = "list"
+				m.notice = fmt.Sprintf("Saved template %s", t.Name)
+				return m, fetchTemplates
+			}
+			var cmd tea.Cmd
+			m.editor, cmd = m.editor.Update(msg)
+			return m, cmd
+
+		case "variables":
+			if msg.String() == "esc" {
+				m.state = "list"
+				return m, nil
+			}
+		}
+	}
+
+	switch m.state {
+	case "list":
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+
+	case "edit":
+		var cmd tea.Cmd
+		m.editor, cmd = m.editor.Update(msg)
+		return m, cmd
+
+	case "variables":
+		newForm, cmd := m.varForm.Update(msg)
+		if form, ok := newForm.(*huh.Form); ok {
+			m.varForm = form
+		}
+		if m.varForm.State == huh.StateCompleted {
+			return m, m.substitute()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// use applies a template, asking for its variables first if it has any
+func (m *TemplateModel) use(t *templates.Template) tea.Cmd {
+	m.selected = t
+	m.variables = t.Variables()
+	if len(m.variables) == 0 {
+		return m.substitute()
+	}
+
+	// Prefill values from the environment; empty inputs use the defaults
+	m.values = make([]string, len(m.variables))
+	fields := make([]huh.Field, len(m.variables))
+	for i, v := range m.variables {
+		m.values[i] = os.Getenv(v.Name)
+		input := huh.NewInput().
+			Title(v.Name).
+			Value(&m.values[i])
+		if v.Default != "" {
+			input = input.Placeholder(v.Default)
+		}
+		fields[i] = input
+	}
+
+	m.varForm = huh.NewForm(huh.NewGroup(fields...)).WithWidth(m.width - 4).WithShowHelp(true)
+	m.state = "variables"
+	return m.varForm.Init()
+}
+
+// substitute fills in the variables of the selected template and hands it
+// to the container view
+func (m *TemplateModel) substitute() tea.Cmd {
+	values := map[string]string{}
+	for i, v := range m.variables {
+		if m.values != nil && m.values[i] != "" {
+			values[v.Name] = m.values[i]
+		}
+	}
+
+	t, err := m.selected.Substitute(values)
+	m.state = "list"
+	m.values = nil
+	if err != nil {
+		m.error = err
+		return nil
+	}
+	return func() tea.Msg { return TemplateUseMsg{Template: t} }
+}
+
+// openEditor shows the YAML editor for a template
+func (m *TemplateModel) openEditor(name, content string) tea.Cmd {
+	editor := textarea.New()
+	editor.ShowLineNumbers = true
+	editor.CharLimit = 0
+	editor.SetWidth(m.width - 8)
+	editor.SetHeight(m.height - 12)
+	editor.SetValue(content)
+
+	m.editor = editor
+	m.editName = name
+	m.editErr = ""
+	m.editOverwrite = ""
+	m.state = "edit"
+	return m.editor.Focus()
+}
+
+// View renders the template view
+func (m *TemplateModel) View() string {
+	switch m.state {
+	case "confirm":
+		return StyleMainLayout.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				StyleTitle.Render("Confirm Action"),
+				"",
+				StyleInfoBox.Render(lipgloss.JoinVertical(lipgloss.Left,
+					fmt.Sprintf("Delete template %s?", m.selected.Name),
+					"",
+					"Press (y)es to confirm or (n)o to cancel",
+				)),
+			),
+		)
+
+	case "edit":
+		title := "New Template"
+		if m.editName != "" {
+			title = fmt.Sprintf("Edit Template %s", m.editName)
+		}
+		sections := []string{StyleTitle.Render(title), "", m.editor.View()}
+		if m.editErr != "" {
+			sections = append(sections, "", StyleError.Render(m.editErr))
+		}
+		sections = append(sections, "",
+			StyleHelp.Render("Values use the create form syntax; $NAME, ${NAME} and ${NAME:-default} are asked for when the template is used"),
+			StyleHelp.Render("ctrl+s: Save • Esc: Discard"),
+		)
+		return StyleMainLayout.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+
+	case "variables":
+		return StyleMainLayout.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				StyleTitle.Render(fmt.Sprintf("Variables for %s", m.selected.Name)),
+				"",
+				m.varForm.View(),
+				"",
+				StyleHelp.Render("Empty values fall back to the template default • Esc: Back"),
+			),
+		)
+	}
+
+	sections := []string{m.list.View()}
+	if m.error != nil {
+		sections = append(sections, StyleError.Render(fmt.Sprintf("Error: %v", m.error)))
+	}
+	if m.notice != "" {
+		sections = append(sections, StyleSuccess.Render(m.notice))
+	}
+	if len(m.list.Items()) == 0 && m.error == nil {
+		dir, _ := templates.Dir()
+		sections = append(sections, StyleSubtle.Render(fmt.Sprintf(
+			"No templates yet. Press n to write one, or ctrl+s in the create form to save its values. Templates are stored in %s", dir)))
+	}
+	return StyleMainLayout.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
@@ -0,0 +1,346 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/internal/client"
+	"github.com/Gostatsog/dockerNav/internal/registryauth"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// healthTimeout bounds how long a recreated container may take to report healthy
+const healthTimeout = 2 * time.Minute
+
+// ContainerUpdateProgressMsg carries a single step of the update workflow
+type ContainerUpdateProgressMsg struct {
+	Step string
+}
+
+// ContainerUpdateDoneMsg signals the end of the update workflow
+type ContainerUpdateDoneMsg struct {
+	ContainerID string
+	Updated     bool
+	Error       error
+}
+
+// waitForUpdate returns a command that waits for the next update message
+func waitForUpdate(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// updateContainerImage pulls the image of a container and recreates the
+// container on it when the image changed. Progress is sent on the returned
+// channel, which is closed once a ContainerUpdateDoneMsg has been delivered.
+func updateContainerImage(docker *client.DockerClient, containerID string) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+
+		step := func(format string, args ...interface{}) {
+			updates <- ContainerUpdateProgressMsg{Step: fmt.Sprintf(format, args...)}
+		}
+
+		newID, updated, err := recreateWithNewImage(docker, containerID, step)
+		updates <- ContainerUpdateDoneMsg{
+			ContainerID: newID,
+			Updated:     updated,
+			Error:       err,
+		}
+	}()
+
+	return updates
+}
+
+// recreateWithNewImage performs the pull, compare, recreate and rollback steps
+func recreateWithNewImage(docker *client.DockerClient, containerID string, step func(string, ...interface{})) (string, bool, error) {
+	ctx := context.Background()
+	cli := docker.Client
+
+	old, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return containerID, false, err
+	}
+	name := strings.TrimPrefix(old.Name, "/")
+	ref := old.Config.Image
+
+	// Values the container inherited from the old image are told apart from
+	// the ones set on it, so the new image can supply its own
+	oldImage, err := cli.ImageInspect(ctx, old.Image)
+	if err != nil {
+		return containerID, false, fmt.Errorf("inspect image %s: %w", shortImageID(old.Image), err)
+	}
+
+	step("Pulling %s", ref)
+	options, err := registryauth.PullOptions(ref)
+	if err != nil {
+		return containerID, false, fmt.Errorf("pull %s: %w", ref, err)
+	}
+	reader, err := cli.ImagePull(ctx, ref, options)
+	if err != nil {
+		return containerID, false, fmt.Errorf("pull %s: %w", ref, err)
+	}
+	err = decodePullStream(reader, func(jsonmessage.JSONMessage) {})
+	reader.Close()
+	if err != nil {
+		return containerID, false, fmt.Errorf("pull %s: %w", ref, err)
+	}
+
+	pulled, err := cli.ImageInspect(ctx, ref)
+	if err != nil {
+		return containerID, false, err
+	}
+	if pulled.ID == old.Image {
+		step("Image %s is up to date (%s)", ref, shortImageID(pulled.ID))
+		return containerID, false, nil
+	}
+	step("New image %s available (was %s)", shortImageID(pulled.ID), shortImageID(old.Image))
+
+	wasRunning := old.State != nil && old.State.Running
+	if wasRunning {
+		step("Stopping %s", name)
+		if err := cli.ContainerStop(ctx, old.ID, container.StopOptions{}); err != nil {
+			return containerID, false, fmt.Errorf("stop %s: %w", name, err)
+		}
+	}
+
+	asideName := fmt.Sprintf("%s_old_%s", name, old.ID[:12])
+	step("Renaming %s to %s", name, asideName)
+	if err := cli.ContainerRename(ctx, old.ID, asideName); err != nil {
+		if wasRunning {
+			_ = cli.ContainerStart(ctx, old.ID, container.StartOptions{})
+		}
+		return containerID, false, fmt.Errorf("rename %s: %w", name, err)
+	}
+
+	rollback := func(newID string, cause error) (string, bool, error) {
+		step("Rolling back: %v", cause)
+		if newID != "" {
+			_ = cli.ContainerRemove(ctx, newID, container.RemoveOptions{Force: true})
+		}
+		if err := cli.ContainerRename(ctx, old.ID, name); err != nil {
+			return old.ID, false, fmt.Errorf("%v; rollback rename failed: %w", cause, err)
+		}
+		if wasRunning {
+			if err := cli.ContainerStart(ctx, old.ID, container.StartOptions{}); err != nil {
+				return old.ID, false, fmt.Errorf("%v; rollback start failed: %w", cause, err)
+			}
+		}
+		step("Restored %s", name)
+		return old.ID, false, cause
+	}
+
+	config := cloneConfig(old.Config, old.ID)
+	if oldImage.Config != nil {
+		dropImageDefaults(&config, oldImage.Config)
+	}
+	hostConfig := *old.HostConfig
+	hostConfig.Mounts = append(slices.Clone(hostConfig.Mounts), anonymousVolumes(old)...)
+
+	primary, extra := cloneEndpoints(old.HostConfig.NetworkMode, old.NetworkSettings)
+
+	step("Creating %s on %s", name, shortImageID(pulled.ID))
+	created, err := cli.ContainerCreate(ctx, &config, &hostConfig, primary, nil, name)
+	if err != nil {
+		return rollback("", fmt.Errorf("create: %w", err))
+	}
+
+	for netName, endpoint := range extra {
+		step("Connecting to network %s", netName)
+		if err := cli.NetworkConnect(ctx, netName, created.ID, endpoint); err != nil {
+			return rollback(created.ID, fmt.Errorf("connect %s: %w", netName, err))
+		}
+	}
+
+	if wasRunning {
+		step("Starting %s", name)
+		if err := cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+			return rollback(created.ID, fmt.Errorf("start: %w", err))
+		}
+		if err := waitUntilHealthy(ctx, docker, created.ID, step); err != nil {
+			return rollback(created.ID, err)
+		}
+	}
+
+	step("Removing old container %s", asideName)
+	if err := cli.ContainerRemove(ctx, old.ID, container.RemoveOptions{}); err != nil {
+		step("Could not remove %s: %v", asideName, err)
+	}
+
+	step("Updated %s to %s", name, shortImageID(pulled.ID))
+	return created.ID, true, nil
+}
+
+// cloneConfig copies the config of a container to create another one from
+// it. A hostname equal to the short ID of the container was generated by the
+// daemon and is left for the daemon to generate again.
+func cloneConfig(config *container.Config, id string) container.Config {
+	clone := *config
+	if clone.Hostname == id[:12] {
+		clone.Hostname = ""
+	}
+	return clone
+}
+
+// dropImageDefaults clears the values of a copied container config that
+// equal those of the image the container was created from
+func dropImageDefaults(config, image *container.Config) {
+	var env []string
+	for _, kv := range config.Env {
+		if !slices.Contains(image.Env, kv) {
+			env = append(env, kv)
+		}
+	}
+	config.Env = env
+
+	// An entrypoint set on the container discards the command of the image,
+	// so the command is then the container's own
+	if slices.Equal(config.Entrypoint, image.Entrypoint) {
+		config.Entrypoint = nil
+		if slices.Equal(config.Cmd, image.Cmd) {
+			config.Cmd = nil
+		}
+	}
+	if config.WorkingDir == image.WorkingDir {
+		config.WorkingDir = ""
+	}
+
+	labels := map[string]string{}
+	for k, v := range config.Labels {
+		if value, ok := image.Labels[k]; !ok || value != v {
+			labels[k] = v
+		}
+	}
+	config.Labels = labels
+}
+
+// anonymousVolumes returns mounts re-attaching the volumes a container got
+// without naming them in its binds or mounts, such as those the image
+// declares, so their data carries over to the new container
+func anonymousVolumes(c container.InspectResponse) []mount.Mount {
+	declared := map[string]bool{}
+	for _, bind := range c.HostConfig.Binds {
+		if parts := strings.Split(bind, ":"); len(parts) >= 2 {
+			declared[parts[1]] = true
+		}
+	}
+	for _, m := range c.HostConfig.Mounts {
+		declared[m.Target] = true
+	}
+
+	var mounts []mount.Mount
+	for _, m := range c.Mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" || declared[m.Destination] {
+			continue
+		}
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeVolume,
+			Source:   m.Name,
+			Target:   m.Destination,
+			ReadOnly: !m.RW,
+		})
+	}
+	return mounts
+}
+
+// cloneEndpoints copies the user-set endpoint settings of a container. The
+// endpoint matching the network mode is returned as the create-time config,
+// the others have to be connected after creation.
+func cloneEndpoints(networkMode container.NetworkMode, settings *container.NetworkSettings) (*network.NetworkingConfig, map[string]*network.EndpointSettings) {
+	primary := &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{}}
+	extra := map[string]*network.EndpointSettings{}
+	if settings == nil {
+		return primary, extra
+	}
+
+	for netName, ep := range settings.Networks {
+		if ep == nil {
+			continue
+		}
+		clone := &network.EndpointSettings{
+			IPAMConfig: ep.IPAMConfig,
+			Links:      ep.Links,
+			Aliases:    ep.Aliases,
+			DriverOpts: ep.DriverOpts,
+			MacAddress: ep.MacAddress,
+		}
+		if netName == string(networkMode) || (networkMode.IsDefault() && netName == "bridge") {
+			primary.EndpointsConfig[netName] = clone
+		} else {
+			extra[netName] = clone
+		}
+	}
+
+	// Containers on a user network without a matching mode still need one
+	if len(primary.EndpointsConfig) == 0 {
+		for netName, clone := range extra {
+			primary.EndpointsConfig[netName] = clone
+			delete(extra, netName)
+			break
+		}
+	}
+
+	return primary, extra
+}
+
+// waitUntilHealthy waits for a started container to report healthy, or for
+// containers without a healthcheck, to still be running after a grace period
+func waitUntilHealthy(ctx context.Context, docker *client.DockerClient, containerID string, step func(string, ...interface{})) error {
+	deadline := time.Now().Add(healthTimeout)
+	announced := false
+
+	for {
+		time.Sleep(2 * time.Second)
+
+		info, err := docker.Client.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return err
+		}
+		if info.State == nil || !info.State.Running {
+			return fmt.Errorf("container exited after start")
+		}
+		if info.State.Health == nil {
+			step("Container is running")
+			return nil
+		}
+
+		switch info.State.Health.Status {
+		case container.Healthy:
+			step("Container is healthy")
+			return nil
+		case container.Unhealthy:
+			return fmt.Errorf("container became unhealthy")
+		}
+
+		if !announced {
+			step("Waiting for healthcheck")
+			announced = true
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("container not healthy after %s", healthTimeout)
+		}
+	}
+}
+
+// shortImageID trims the digest prefix of an image ID for display
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
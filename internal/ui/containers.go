@@ -12,6 +12,8 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -95,28 +97,45 @@ type ContainerItem struct {
 	container Summary
 	title     string
 	desc      string
+	marked    bool
 }
 
 // FilterValue implements list.Item interface
 func (i ContainerItem) FilterValue() string { return i.title }
 
 // Title returns the title for the list item
-func (i ContainerItem) Title() string { return i.title }
+func (i ContainerItem) Title() string {
+	if i.marked {
+		return "● " + i.title
+	}
+	return i.title
+}
 
 // Description returns the description for the list item
 func (i ContainerItem) Description() string { return i.desc }
 
 // ContainerKeyMap defines keybindings for container operations
 type ContainerKeyMap struct {
-	Refresh  key.Binding
-	Logs     key.Binding
-	Stop     key.Binding
-	Start    key.Binding
-	Restart  key.Binding
-	Remove   key.Binding
-	Create   key.Binding
-	Back     key.Binding
-	MainMenu key.Binding
+	Refresh   key.Binding
+	Logs      key.Binding
+	Stop      key.Binding
+	Start     key.Binding
+	Restart   key.Binding
+	Remove    key.Binding
+	Create    key.Binding
+	Import    key.Binding
+	Templates key.Binding
+	Update    key.Binding
+	Pause     key.Binding
+	Mark      key.Binding
+	MarkAll   key.Binding
+	Invert    key.Binding
+	Filter    key.Binding
+	Running   key.Binding
+	Group     key.Binding
+	GroupBy   key.Binding
+	Back      key.Binding
+	MainMenu  key.Binding
 }
 
 // DefaultContainerKeyMap returns default container keybindings
@@ -150,6 +169,50 @@ func DefaultContainerKeyMap() ContainerKeyMap {
 			key.WithKeys("c"),
 			key.WithHelp("c", "create"),
 		),
+		Import: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "import docker run"),
+		),
+		Templates: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "templates"),
+		),
+		Update: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "update image"),
+		),
+		Pause: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pause/unpause"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark"),
+		),
+		MarkAll: key.NewBinding(
+			key.WithKeys("ctrl+a"),
+			key.WithHelp("ctrl+a", "mark all"),
+		),
+		Invert: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "invert marks"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "filter bar"),
+		),
+		Running: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "running only"),
+		),
+		Group: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "group"),
+		),
+		GroupBy: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "group by label"),
+		),
 		Back: key.NewBinding(
 			key.WithKeys("esc", "backspace"),
 			key.WithHelp("esc", "back"),
@@ -167,7 +230,7 @@ type ContainerModel struct {
 	containerList     list.Model
 	selectedContainer *Summary
 	keyMap            ContainerKeyMap
-	state             string // "list", "logs", "confirm", "create"
+	state             string // "list", "logs", "confirm", "create", "templates", "update", "results", "columns", "filter", "groupBy"
 	width             int
 	height            int
 	showAll           bool
@@ -177,7 +240,32 @@ type ContainerModel struct {
 	loading           bool
 	error             error
 	createModel       *ContainerCreateModel // Form for container creation
+	templateModel     *TemplateModel        // Saved create form templates
 	spinner           spinner.Model
+	updates           <-chan tea.Msg // Progress of a running image update
+	updateSteps       []string
+	updating          bool
+	containers        []Summary       // Last fetched containers
+	marked            map[string]bool // IDs of containers marked for bulk actions
+	bulkTargets       []Summary
+	bulkResults       []ContainerBulkResult
+	tableMode         bool        // Show containers in a table instead of the list
+	table             table.Model // Table used in table mode
+	tableKeys         ContainerTableKeyMap
+	tableRows         []Summary // Containers in table row order
+	layout            TableLayout
+	columnFocus       int // Focused column among the visible ones
+	columnCursor      int // Cursor in the column chooser
+	filter            ContainerFilter
+	filterInput       textinput.Model
+	filterNameInput   textinput.Model // Name prompt when saving a filter
+	filterSaving      bool
+	filterErr         error
+	savedFilters      map[string]string
+	groupLabel        string // Label containers are grouped by, empty when not grouped
+	groupLabelInput   textinput.Model
+	collapsed         map[string]bool // Collapsed group names
+	focusID           string          // Container to select once the list is refreshed
 }
 
 // NewContainerModel creates a new container model
@@ -209,6 +297,17 @@ func NewContainerModel(docker *client.DockerClient) *ContainerModel {
 			keyMap.Restart,
 			keyMap.Remove,
 			keyMap.Create,
+			keyMap.Import,
+			keyMap.Templates,
+			keyMap.Update,
+			keyMap.Pause,
+			keyMap.Mark,
+			keyMap.MarkAll,
+			keyMap.Invert,
+			keyMap.Filter,
+			keyMap.Running,
+			keyMap.Group,
+			keyMap.GroupBy,
 			keyMap.Back,
 			keyMap.MainMenu,
 		}
@@ -225,15 +324,44 @@ func NewContainerModel(docker *client.DockerClient) *ContainerModel {
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(ColorPrimary)
 
+	layout := loadTableLayout()
+
+	// Set up the structured filter bar
+	fi := textinput.New()
+	fi.Placeholder = "state=running label=team=payments image=nginx network=backend name~api"
+	fi.Prompt = "Filter: "
+	fi.PromptStyle = lipgloss.NewStyle().Foreground(ColorPrimary)
+
+	ni := textinput.New()
+	ni.Placeholder = "filter name"
+	ni.Prompt = "Save as: "
+	ni.PromptStyle = lipgloss.NewStyle().Foreground(ColorPrimary)
+
+	gi := textinput.New()
+	gi.Placeholder = composeProjectLabel
+	gi.Prompt = "Group by label: "
+	gi.PromptStyle = lipgloss.NewStyle().Foreground(ColorPrimary)
+	gi.SetValue(composeProjectLabel)
+
 	return &ContainerModel{
-		docker:        docker,
-		containerList: containerList,
-		keyMap:        keyMap,
-		state:         "list",
-		showAll:       true,
-		viewport:      vp,
-		loading:       true,
-		spinner:       s,
+		docker:          docker,
+		containerList:   containerList,
+		keyMap:          keyMap,
+		state:           "list",
+		showAll:         true,
+		viewport:        vp,
+		loading:         true,
+		spinner:         s,
+		marked:          map[string]bool{},
+		table:           newContainerTable(),
+		tableKeys:       DefaultContainerTableKeyMap(),
+		layout:          layout,
+		tableMode:       layout.Enabled,
+		filterInput:     fi,
+		filterNameInput: ni,
+		savedFilters:    loadSavedFilters(),
+		groupLabelInput: gi,
+		collapsed:       map[string]bool{},
 	}
 }
 
@@ -253,6 +381,7 @@ func (m *ContainerModel) Init() tea.Cmd {
 	}
 
 	m.containerList.SetSize(listWidth, listHeight)
+	m.resizeTable(listWidth, listHeight-2)
 
 	// Update viewport dimensions for logs view
 	m.viewport.Width = m.width - 4
@@ -325,14 +454,154 @@ func containerSummaryToSummary(c container.Summary) Summary {
 	}
 }
 
+// newContainerItem builds the list item for a container
+func (m *ContainerModel) newContainerItem(c Summary) ContainerItem {
+	// Format created time
+	createdTime := time.Unix(c.Created, 0)
+	created := formatter.FormatTime(createdTime)
+
+	// Include state in description using color formatting
+	stateStyle := StyleTableRow
+	switch c.State {
+	case "running":
+		stateStyle = StyleSuccess
+	case "exited":
+		stateStyle = StyleSubtle
+	case "created", "paused":
+		stateStyle = StyleWarning
+	}
+
+	status := stateStyle.Render(c.Status)
+
+	desc := fmt.Sprintf("ID: %s • Image: %s • Created: %s • Status: %s",
+		c.ID[:12],
+		c.Image,
+		created,
+		status,
+	)
+
+	return ContainerItem{
+		container: c,
+		title:     containerName(c),
+		desc:      desc,
+		marked:    m.marked[c.ID],
+	}
+}
+
+// refreshItems rebuilds the list items and table rows from the last
+// fetched containers
+func (m *ContainerModel) refreshItems() tea.Cmd {
+	items := make([]list.Item, 0, len(m.containers))
+	if m.groupLabel != "" {
+		items = m.groupedItems()
+	} else {
+		for _, c := range m.containers {
+			items = append(items, m.newContainerItem(c))
+		}
+	}
+	m.refreshTable()
+
+	m.containerList.Title = "Containers"
+	if summary := m.filterSummary(); summary != "" {
+		m.containerList.Title = fmt.Sprintf("Containers (%s)", summary)
+	}
+	if m.groupLabel != "" {
+		m.containerList.Title += fmt.Sprintf(" grouped by %s", m.groupLabel)
+	}
+	return m.containerList.SetItems(items)
+}
+
+// selectedItem returns the container under the cursor of the active view
+func (m *ContainerModel) selectedItem() (ContainerItem, bool) {
+	if m.tableMode {
+		cursor := m.table.Cursor()
+		if cursor < 0 || cursor >= len(m.tableRows) {
+			return ContainerItem{}, false
+		}
+		return m.newContainerItem(m.tableRows[cursor]), true
+	}
+	item, ok := m.containerList.SelectedItem().(ContainerItem)
+	return item, ok
+}
+
+// focusContainer shows every container, clearing filters and folded
+// groups, so that a container can be selected once the list is refreshed
+func (m *ContainerModel) focusContainer(id string) {
+	m.focusID = id
+	m.showAll = true
+	m.filter = ContainerFilter{}
+	m.filterInput.SetValue("")
+	m.collapsed = map[string]bool{}
+	m.containerList.ResetFilter()
+	m.state = "list"
+}
+
+// selectFocused moves the cursor of the active view to the focused container
+func (m *ContainerModel) selectFocused() {
+	if m.focusID == "" {
+		return
+	}
+	if m.tableMode {
+		for i, c := range m.tableRows {
+			if c.ID == m.focusID {
+				m.table.SetCursor(i)
+			}
+		}
+	} else {
+		for i, it := range m.containerList.Items() {
+			if item, ok := it.(ContainerItem); ok && item.container.ID == m.focusID {
+				m.containerList.Select(i)
+			}
+		}
+	}
+	m.focusID = ""
+}
+
+// visibleContainers returns the containers shown by the active view
+func (m *ContainerModel) visibleContainers() []Summary {
+	if m.tableMode {
+		return m.tableRows
+	}
+	var visible []Summary
+	for _, it := range m.containerList.VisibleItems() {
+		if item, ok := it.(ContainerItem); ok {
+			visible = append(visible, item.container)
+		}
+	}
+	return visible
+}
+
+// moveCursorDown advances the cursor of the active view by one row
+func (m *ContainerModel) moveCursorDown() {
+	if m.tableMode {
+		m.table.MoveDown(1)
+		return
+	}
+	m.containerList.CursorDown()
+}
+
+// resizeTable fits the table to the current window size
+func (m *ContainerModel) resizeTable(width, height int) {
+	m.table.SetWidth(width)
+	m.table.SetHeight(height)
+}
+
 // fetchContainers returns a command that fetches container data
 func (m *ContainerModel) fetchContainers() tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		containers, err := m.docker.Client.ContainerList(ctx, container.ListOptions{All: m.showAll})
-		summaries := make([]Summary, len(containers))
-		for i, c := range containers {
-			summaries[i] = containerSummaryToSummary(c)
+		containers, err := m.docker.Client.ContainerList(ctx, container.ListOptions{
+			All:     m.showAll,
+			Size:    m.sizeColumnVisible(),
+			Filters: m.filter.Args(),
+		})
+		summaries := make([]Summary, 0, len(containers))
+		for _, c := range containers {
+			summary := containerSummaryToSummary(c)
+			// Terms the daemon cannot evaluate are matched here
+			if m.filter.Match(summary) {
+				summaries = append(summaries, summary)
+			}
 		}
 		return ContainerListMsg{
 			Containers: summaries,
@@ -372,24 +641,31 @@ func (m *ContainerModel) fetchContainerLogs(containerID string) tea.Cmd {
 	}
 }
 
+// containerAction performs a single action on a container
+func (m *ContainerModel) containerAction(ctx context.Context, action string, containerID string) error {
+	switch action {
+	case "stop":
+		timeout := 10 // seconds
+		return m.docker.Client.ContainerStop(ctx, containerID, container.StopOptions{Timeout: &timeout})
+	case "start":
+		return m.docker.Client.ContainerStart(ctx, containerID, container.StartOptions{})
+	case "restart":
+		timeout := 10 // seconds
+		return m.docker.Client.ContainerRestart(ctx, containerID, container.StopOptions{Timeout: &timeout})
+	case "remove":
+		return m.docker.Client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: false})
+	case "pause":
+		return m.docker.Client.ContainerPause(ctx, containerID)
+	case "unpause":
+		return m.docker.Client.ContainerUnpause(ctx, containerID)
+	}
+	return fmt.Errorf("unknown action: %s", action)
+}
+
 // performContainerAction returns a command that performs an action on a container
 func (m *ContainerModel) performContainerAction(action string, containerID string) tea.Cmd {
 	return func() tea.Msg {
-		ctx := context.Background()
-		var err error
-
-		switch action {
-		case "stop":
-			timeout := 10 // seconds
-			err = m.docker.Client.ContainerStop(ctx, containerID, container.StopOptions{Timeout: &timeout})
-		case "start":
-			err = m.docker.Client.ContainerStart(ctx, containerID, container.StartOptions{})
-		case "restart":
-			timeout := 10 // seconds
-			err = m.docker.Client.ContainerRestart(ctx, containerID, container.StopOptions{Timeout: &timeout})
-		case "remove":
-			err = m.docker.Client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: false})
-		}
+		err := m.containerAction(context.Background(), action, containerID)
 
 		return ContainerActionMsg{
 			Action:      action,
@@ -399,6 +675,22 @@ func (m *ContainerModel) performContainerAction(action string, containerID strin
 	}
 }
 
+// CapturingInput reports whether a text input currently has focus
+func (m *ContainerModel) CapturingInput() bool {
+	switch m.state {
+	case "create", "filter", "groupBy":
+		return true
+	case "update":
+		// Leaving mid-update could strand the old container renamed aside
+		return m.updating
+	case "templates":
+		return m.templateModel.CapturingInput()
+	case "list":
+		return m.containerList.SettingFilter()
+	}
+	return false
+}
+
 // Update handles messages and updates the model
 func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
@@ -407,8 +699,14 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		// Handle container creation view separately
 		if m.state == "create" {
-			if msg.String() == "esc" {
+			if msg.String() == "esc" && !m.createModel.handlesEsc() {
+				// Stop a pull or log stream still running for the form
+				m.createModel.stop()
 				m.state = "list"
+				if m.createModel.result != "" {
+					m.loading = true
+					return m, m.fetchContainers()
+				}
 				return m, nil
 			}
 
@@ -420,9 +718,117 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+		// Handle the template view separately
+		if m.state == "templates" {
+			if msg.String() == "esc" && m.templateModel.atTop() {
+				m.state = "list"
+				return m, nil
+			}
+
+			var cmd tea.Cmd
+			m.templateModel, cmd = m.templateModel.Update(msg)
+			return m, cmd
+		}
+
 		switch m.state {
 		case "list":
+			// Let the list consume keys while the filter is being typed
+			if m.containerList.SettingFilter() {
+				break
+			}
+
+			// Bulk actions apply to the marked containers when there are any
+			if len(m.marked) > 0 {
+				switch {
+				case key.Matches(msg, m.keyMap.Stop):
+					m.confirmBulk("stop", m.markedContainers())
+					return m, nil
+				case key.Matches(msg, m.keyMap.Start):
+					m.confirmBulk("start", m.markedContainers())
+					return m, nil
+				case key.Matches(msg, m.keyMap.Restart):
+					m.confirmBulk("restart", m.markedContainers())
+					return m, nil
+				case key.Matches(msg, m.keyMap.Remove):
+					m.confirmBulk("remove", m.markedContainers())
+					return m, nil
+				case key.Matches(msg, m.keyMap.Pause):
+					m.confirmBulk("pause", m.markedContainers())
+					return m, nil
+				}
+			}
+
+			// Group headings act on every container of the group
+			if group, ok := m.selectedGroup(); ok {
+				switch {
+				case key.Matches(msg, m.keyMap.Stop):
+					m.confirmBulk("stop", group.containers)
+					return m, nil
+				case key.Matches(msg, m.keyMap.Start):
+					m.confirmBulk("start", group.containers)
+					return m, nil
+				case key.Matches(msg, m.keyMap.Restart):
+					m.confirmBulk("restart", group.containers)
+					return m, nil
+				case key.Matches(msg, m.keyMap.Mark):
+					for _, c := range group.containers {
+						m.marked[c.ID] = true
+					}
+					return m, m.refreshItems()
+				case msg.String() == "enter":
+					return m, m.toggleCollapsed(group)
+				}
+			}
+
+			if m.tableMode {
+				if handled, err := m.handleTableKey(msg); handled {
+					m.error = err
+					return m, nil
+				}
+			}
+
 			switch {
+			case key.Matches(msg, m.tableKeys.Toggle):
+				hadSizes := m.sizeColumnVisible()
+				m.error = m.setTableMode(!m.tableMode)
+				m.refreshTable()
+				// Sizes are only requested while the size column is shown
+				if !hadSizes && m.sizeColumnVisible() {
+					m.loading = true
+					return m, tea.Batch(m.fetchContainers(), m.spinner.Tick)
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.Filter):
+				m.filterInput.SetValue(m.filter.Expr)
+				m.filterInput.CursorEnd()
+				m.filterErr = nil
+				m.filterSaving = false
+				m.state = "filter"
+				return m, m.filterInput.Focus()
+
+			case key.Matches(msg, m.keyMap.Running):
+				m.showAll = !m.showAll
+				m.loading = true
+				return m, tea.Batch(m.fetchContainers(), m.spinner.Tick)
+
+			case key.Matches(msg, m.keyMap.Group):
+				return m, m.toggleGrouping()
+
+			case key.Matches(msg, m.keyMap.GroupBy):
+				m.state = "groupBy"
+				m.groupLabelInput.CursorEnd()
+				return m, m.groupLabelInput.Focus()
+
+			case key.Matches(msg, m.keyMap.Mark):
+				return m, m.toggleMark()
+
+			case key.Matches(msg, m.keyMap.MarkAll):
+				return m, m.markVisible()
+
+			case key.Matches(msg, m.keyMap.Invert):
+				return m, m.invertMarks()
+
 			case key.Matches(msg, m.keyMap.Back):
 				// Only at the list level do we return to main menu
 				return m, func() tea.Msg {
@@ -439,14 +845,14 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Batch(m.fetchContainers(), m.spinner.Tick)
 
 			case key.Matches(msg, m.keyMap.Logs):
-				if item, ok := m.containerList.SelectedItem().(ContainerItem); ok {
+				if item, ok := m.selectedItem(); ok {
 					m.selectedContainer = &item.container
 					m.state = "logs"
 					return m, m.fetchContainerLogs(item.container.ID)
 				}
 
 			case key.Matches(msg, m.keyMap.Stop):
-				if item, ok := m.containerList.SelectedItem().(ContainerItem); ok {
+				if item, ok := m.selectedItem(); ok {
 					m.selectedContainer = &item.container
 					m.confirmMsg = fmt.Sprintf("Are you sure you want to stop container %s?", strings.TrimPrefix(item.container.Names[0], "/"))
 					m.confirmAction = "stop"
@@ -455,7 +861,7 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case key.Matches(msg, m.keyMap.Start):
-				if item, ok := m.containerList.SelectedItem().(ContainerItem); ok {
+				if item, ok := m.selectedItem(); ok {
 					m.selectedContainer = &item.container
 					m.confirmMsg = fmt.Sprintf("Are you sure you want to start container %s?", strings.TrimPrefix(item.container.Names[0], "/"))
 					m.confirmAction = "start"
@@ -464,7 +870,7 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case key.Matches(msg, m.keyMap.Restart):
-				if item, ok := m.containerList.SelectedItem().(ContainerItem); ok {
+				if item, ok := m.selectedItem(); ok {
 					m.selectedContainer = &item.container
 					m.confirmMsg = fmt.Sprintf("Are you sure you want to restart container %s?", strings.TrimPrefix(item.container.Names[0], "/"))
 					m.confirmAction = "restart"
@@ -473,7 +879,7 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case key.Matches(msg, m.keyMap.Remove):
-				if item, ok := m.containerList.SelectedItem().(ContainerItem); ok {
+				if item, ok := m.selectedItem(); ok {
 					m.selectedContainer = &item.container
 					m.confirmMsg = fmt.Sprintf("Are you sure you want to remove container %s?", strings.TrimPrefix(item.container.Names[0], "/"))
 					m.confirmAction = "remove"
@@ -481,6 +887,25 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 
+			case key.Matches(msg, m.keyMap.Pause):
+				if item, ok := m.selectedItem(); ok {
+					m.selectedContainer = &item.container
+					action := resolveAction("pause", item.container)
+					m.confirmMsg = fmt.Sprintf("Are you sure you want to %s container %s?", action, strings.TrimPrefix(item.container.Names[0], "/"))
+					m.confirmAction = action
+					m.state = "confirm"
+					return m, nil
+				}
+
+			case key.Matches(msg, m.keyMap.Update):
+				if item, ok := m.selectedItem(); ok {
+					m.selectedContainer = &item.container
+					m.confirmMsg = fmt.Sprintf("Pull %s and recreate container %s if a newer image exists?", item.container.Image, strings.TrimPrefix(item.container.Names[0], "/"))
+					m.confirmAction = "update"
+					m.state = "confirm"
+					return m, nil
+				}
+
 			case key.Matches(msg, m.keyMap.Create):
 				// Initialize container creation model
 				m.createModel = NewContainerCreateModel(m.docker)
@@ -488,6 +913,20 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.createModel.height = m.height
 				m.state = "create"
 				return m, m.createModel.Init()
+
+			case key.Matches(msg, m.keyMap.Import):
+				// Open the create form behind a docker run import prompt
+				m.createModel = NewContainerCreateModel(m.docker)
+				m.createModel.width = m.width
+				m.createModel.height = m.height
+				m.state = "create"
+				return m, tea.Batch(m.createModel.Init(), m.createModel.StartImport())
+
+			case key.Matches(msg, m.keyMap.Templates):
+				m.templateModel = NewTemplateModel()
+				m.templateModel.setSize(m.width, m.height)
+				m.state = "templates"
+				return m, m.templateModel.Init()
 			}
 
 		case "logs":
@@ -502,14 +941,82 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, cmd
 			}
 
+		case "update":
+			switch {
+			case key.Matches(msg, m.keyMap.Back) && !m.updating:
+				m.state = "list"
+				m.loading = true
+				return m, m.fetchContainers()
+			default:
+				var cmd tea.Cmd
+				m.viewport, cmd = m.viewport.Update(msg)
+				return m, cmd
+			}
+
+		case "filter":
+			return m, m.updateFilterBar(msg)
+
+		case "groupBy":
+			return m, m.updateGroupLabel(msg)
+
+		case "columns":
+			var err error
+			switch msg.String() {
+			case "up", "k":
+				if m.columnCursor > 0 {
+					m.columnCursor--
+				}
+			case "down", "j":
+				if m.columnCursor < len(m.layout.Columns)-1 {
+					m.columnCursor++
+				}
+			case " ", "enter":
+				err = m.toggleColumn()
+			case "K":
+				err = m.moveColumn(-1)
+			case "J":
+				err = m.moveColumn(1)
+			case "esc":
+				m.state = "list"
+				// The size column needs sizes that may not have been fetched
+				if m.sizeColumnVisible() {
+					m.loading = true
+					return m, tea.Batch(m.fetchContainers(), m.spinner.Tick)
+				}
+			}
+			m.error = err
+			return m, nil
+
+		case "results":
+			if key.Matches(msg, m.keyMap.Back) || msg.String() == "enter" {
+				m.state = "list"
+			}
+			return m, nil
+
 		case "confirm":
 			switch msg.String() {
 			case "y", "Y":
+				if len(m.bulkTargets) > 0 {
+					targets := m.bulkTargets
+					m.bulkTargets = nil
+					m.loading = true
+					m.state = "list"
+					return m, tea.Batch(m.performBulkAction(m.confirmAction, targets), m.spinner.Tick)
+				}
+				if m.selectedContainer != nil && m.confirmAction == "update" {
+					m.updates = updateContainerImage(m.docker, m.selectedContainer.ID)
+					m.updateSteps = nil
+					m.updating = true
+					m.viewport.SetContent("")
+					m.state = "update"
+					return m, tea.Batch(waitForUpdate(m.updates), m.spinner.Tick)
+				}
 				if m.selectedContainer != nil {
 					return m, m.performContainerAction(m.confirmAction, m.selectedContainer.ID)
 				}
 				m.state = "list"
 			case "n", "N", "esc":
+				m.bulkTargets = nil
 				m.state = "list"
 			}
 			return m, nil
@@ -533,6 +1040,7 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.containerList.SetSize(listWidth, listHeight)
+		m.resizeTable(listWidth, listHeight-2)
 
 		// Update viewport dimensions
 		m.viewport.Width = m.width - 4
@@ -543,6 +1051,9 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.createModel.width = msg.Width
 			m.createModel.height = msg.Height
 		}
+		if m.templateModel != nil {
+			m.templateModel.setSize(msg.Width, msg.Height)
+		}
 
 		return m, nil
 
@@ -558,41 +1069,21 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		items := make([]list.Item, 0, len(msg.Containers))
+		m.containers = msg.Containers
+
+		// Drop marks of containers that no longer exist
+		present := make(map[string]bool, len(msg.Containers))
 		for _, c := range msg.Containers {
-			name := strings.TrimPrefix(c.Names[0], "/")
-			// Format created time
-			createdTime := time.Unix(c.Created, 0)
-			created := formatter.FormatTime(createdTime)
-
-			// Include state in description using color formatting
-			stateStyle := StyleTableRow
-			switch c.State {
-			case "running":
-				stateStyle = StyleSuccess
-			case "exited":
-				stateStyle = StyleSubtle
-			case "created":
-				stateStyle = StyleWarning
+			present[c.ID] = true
+		}
+		for id := range m.marked {
+			if !present[id] {
+				delete(m.marked, id)
 			}
-
-			status := stateStyle.Render(c.Status)
-
-			desc := fmt.Sprintf("ID: %s • Image: %s • Created: %s • Status: %s",
-				c.ID[:12],
-				c.Image,
-				created,
-				status,
-			)
-
-			items = append(items, ContainerItem{
-				container: c,
-				title:     name,
-				desc:      desc,
-			})
 		}
 
-		cmd := m.containerList.SetItems(items)
+		cmd := m.refreshItems()
+		m.selectFocused()
 		return m, cmd
 
 	case ContainerLogsMsg:
@@ -618,17 +1109,62 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.state = "list"
 		return m, m.fetchContainers()
 
+	case ContainerUpdateProgressMsg:
+		m.updateSteps = append(m.updateSteps, fmt.Sprintf("%s %s", time.Now().Format("15:04:05"), msg.Step))
+		m.viewport.SetContent(strings.Join(m.updateSteps, "\n"))
+		m.viewport.GotoBottom()
+		return m, waitForUpdate(m.updates)
+
+	case ContainerUpdateDoneMsg:
+		m.updating = false
+		switch {
+		case msg.Error != nil:
+			m.updateSteps = append(m.updateSteps, StyleError.Render(fmt.Sprintf("Update failed: %v", msg.Error)))
+		case msg.Updated:
+			m.updateSteps = append(m.updateSteps, StyleSuccess.Render("Update complete"))
+		default:
+			m.updateSteps = append(m.updateSteps, StyleSuccess.Render("Nothing to update"))
+		}
+		m.viewport.SetContent(strings.Join(m.updateSteps, "\n"))
+		m.viewport.GotoBottom()
+		return m, waitForUpdate(m.updates)
+
+	case ContainerBulkMsg:
+		m.loading = false
+		m.bulkResults = msg.Results
+		m.marked = map[string]bool{}
+		m.state = "results"
+		return m, m.fetchContainers()
+
 	case ContainerCreateMsg:
+		// Keep the create view open to show errors or follow the logs
+		if m.state == "create" && (msg.Error != nil || m.createModel.afterCreate == afterCreateLogs) {
+			break
+		}
+
 		// Container was created, refresh the list
 		m.loading = true
 		m.state = "list"
 		return m, m.fetchContainers()
+
+	case TemplateUseMsg:
+		// Open the create form filled from the template
+		m.createModel = NewContainerCreateModel(m.docker)
+		m.createModel.width = m.width
+		m.createModel.height = m.height
+		m.createModel.applyTemplate(msg.Template)
+		m.state = "create"
+		return m, m.createModel.Init()
 	}
 
-	// Update list in list state
+	// Update list or table in list state
 	if m.state == "list" {
 		var cmd tea.Cmd
-		m.containerList, cmd = m.containerList.Update(msg)
+		if m.tableMode {
+			m.table, cmd = m.table.Update(msg)
+		} else {
+			m.containerList, cmd = m.containerList.Update(msg)
+		}
 		cmds = append(cmds, cmd)
 	}
 
@@ -642,6 +1178,13 @@ func (m *ContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	// Update template model if it is shown
+	if m.state == "templates" && m.templateModel != nil {
+		var cmd tea.Cmd
+		m.templateModel, cmd = m.templateModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
@@ -674,10 +1217,36 @@ func (m *ContainerModel) View() string {
 	var content string
 	switch m.state {
 	case "list":
+		listView := m.containerList.View()
+		if m.tableMode {
+			listView = m.table.View()
+		}
 		content = lipgloss.JoinVertical(lipgloss.Left,
 			StyleTitle.Render("Container Management"),
 			"",
-			m.containerList.View(),
+			listView,
+		)
+
+	case "filter":
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Filter Containers"),
+			"",
+			m.renderFilterBar(),
+		)
+
+	case "groupBy":
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Group Containers"),
+			"",
+			StyleInfoBox.Render(m.groupLabelInput.View()),
+			StyleFooter.Render("Enter: Group • Esc: Cancel"),
+		)
+
+	case "columns":
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Table Columns"),
+			"",
+			m.renderColumnChooser(),
 		)
 
 	case "logs":
@@ -711,12 +1280,59 @@ func (m *ContainerModel) View() string {
 		if m.createModel != nil {
 			return m.createModel.View()
 		}
+
+	case "templates":
+		if m.templateModel != nil {
+			return m.templateModel.View()
+		}
+
+	case "results":
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Bulk Action Results"),
+			"",
+			m.renderBulkResults(),
+			StyleFooter.Render("Press esc to go back"),
+		)
+
+	case "update":
+		if m.selectedContainer != nil {
+			name := strings.TrimPrefix(m.selectedContainer.Names[0], "/")
+			footer := "Press esc to go back"
+			if m.updating {
+				footer = fmt.Sprintf("%s Updating...", m.spinner.View())
+			}
+
+			content = lipgloss.JoinVertical(lipgloss.Left,
+				StyleTitle.Render(fmt.Sprintf("Update Image: %s", name)),
+				m.viewport.View(),
+				StyleFooter.Render(footer),
+			)
+		}
 	}
 
 	if m.state == "list" {
 		helpText := StyleHelp.Render(
-			"r: Refresh • l: Logs • s: Stop • a: Start • t: Restart • x: Remove • c: Create • m: Main menu",
+			"r: Refresh • l: Logs • s: Stop • a: Start • t: Restart • p: Pause • x: Remove • c: Create • I: Import docker run • T: Templates • u: Update image • m: Main menu\n" +
+				"space: Mark • ctrl+a: Mark all • i: Invert marks • v: List/table • F: Filter bar • R: Running only • z: Group • Z: Group by",
 		)
+		if m.groupLabel != "" && !m.tableMode {
+			helpText = lipgloss.JoinVertical(lipgloss.Left,
+				helpText,
+				StyleHelp.Render("On a group: enter: Fold • s/a/t: Stop/start/restart group • space: Mark group"),
+			)
+		}
+		if m.tableMode {
+			helpText = lipgloss.JoinVertical(lipgloss.Left,
+				helpText,
+				StyleHelp.Render("[/]: Focus column • o: Sort • +/-: Resize • C: Columns"),
+			)
+		}
+		if len(m.marked) > 0 {
+			helpText = lipgloss.JoinVertical(lipgloss.Left,
+				StyleWarning.Render(fmt.Sprintf("%d marked — s/a/t/p/x apply to all marked containers", len(m.marked))),
+				helpText,
+			)
+		}
 		content = lipgloss.JoinVertical(lipgloss.Left, content, "", helpText)
 	}
 
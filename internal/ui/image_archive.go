@@ -0,0 +1,472 @@
+package ui
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/internal/layers"
+	"github.com/Gostatsog/dockerNav/pkg/formatter"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// archiveNamePattern matches the characters left out of a default archive name
+var archiveNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ImageArchiveProgressMsg carries how many bytes of an archive were written
+// or read
+type ImageArchiveProgressMsg struct {
+	Bytes int64
+}
+
+// ImageLoadProgressMsg carries a message of the load output stream
+type ImageLoadProgressMsg struct {
+	Message jsonmessage.JSONMessage
+}
+
+// ImageArchiveMsg reports the end of a save or load
+type ImageArchiveMsg struct {
+	Error error
+}
+
+// archiveOptions are the values of the save and load forms
+type archiveOptions struct {
+	refs     []string
+	path     string
+	gzip     bool
+	loadPath string
+}
+
+// imageArchive is the state of a running or finished save or load
+type imageArchive struct {
+	action  string // "save" or "load"
+	path    string
+	total   int64 // size of the file to load, or an estimate of the archive
+	bytes   int64
+	layers  *pullProgress
+	loaded  []string
+	bar     progress.Model
+	updates <-chan tea.Msg
+	cancel  context.CancelFunc
+	done    bool
+	err     error
+}
+
+// hostPath expands a leading ~ and makes a path absolute
+func hostPath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+	}
+	return filepath.Abs(p)
+}
+
+// validateSavePath checks that an archive can be written to a path
+func validateSavePath(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("a file path is required")
+	}
+	p, err := hostPath(value)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(filepath.Dir(p)); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", filepath.Dir(p))
+	}
+	if info, err := os.Stat(p); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory", p)
+	}
+	return nil
+}
+
+// validateLoadPath checks that an archive exists at a path
+func validateLoadPath(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("a file path is required")
+	}
+	p, err := hostPath(value)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", p)
+	}
+	return nil
+}
+
+// startSave shows the save form with the selected image picked
+func (m *ImageModel) startSave(item ImageItem) tea.Cmd {
+	picked := imageTags(item.image.RepoTags)
+	if len(picked) == 0 {
+		picked = []string{item.image.ID}
+	}
+	m.archiveOpts.refs = picked
+	m.archiveOpts.gzip = false
+	m.archiveOpts.path = archiveNamePattern.ReplaceAllString(strings.TrimPrefix(item.title, "<none>:<none>"), "_")
+	if strings.Trim(m.archiveOpts.path, "_") == "" {
+		m.archiveOpts.path = shortImageID(item.image.ID)
+	}
+	m.archiveOpts.path += ".tar"
+
+	// Tagged images are saved by tag so the archive keeps their names
+	var options []huh.Option[string]
+	for _, img := range m.images {
+		refs := imageTags(img.RepoTags)
+		if len(refs) == 0 {
+			refs = []string{img.ID}
+		}
+		for _, ref := range refs {
+			label := fmt.Sprintf("%s (%s)", ref, formatter.FormatSize(float64(img.Size)))
+			if ref == img.ID {
+				label = fmt.Sprintf("<none> %s (%s)", shortImageID(img.ID), formatter.FormatSize(float64(img.Size)))
+			}
+			options = append(options, huh.NewOption(label, ref).Selected(slices.Contains(picked, ref)))
+		}
+	}
+
+	o := &m.archiveOpts
+	m.archiveForm = huh.NewForm(huh.NewGroup(
+		huh.NewMultiSelect[string]().
+			Title("Images").
+			Description("Space to pick; every picked image goes into one archive").
+			Options(options...).
+			Height(min(len(options)+2, 10)).
+			Value(&o.refs).
+			Validate(func(refs []string) error {
+				if len(refs) == 0 {
+					return fmt.Errorf("pick at least one image")
+				}
+				return nil
+			}),
+
+		huh.NewInput().
+			Title("Archive Path").
+			Description("File on this host; ~ is your home directory").
+			Value(&o.path).
+			Validate(validateSavePath),
+
+		huh.NewConfirm().
+			Title("Compress with gzip?").
+			Value(&o.gzip),
+	)).WithWidth(m.width - 4).WithShowHelp(true)
+	m.state = "save"
+	return m.archiveForm.Init()
+}
+
+// startLoad shows the load form
+func (m *ImageModel) startLoad() tea.Cmd {
+	o := &m.archiveOpts
+	m.archiveForm = huh.NewForm(huh.NewGroup(
+		huh.NewInput().
+			Title("Archive Path").
+			Description("Archive written by docker save, plain or gzip-compressed").
+			Placeholder("images.tar").
+			Value(&o.loadPath).
+			Validate(validateLoadPath),
+	)).WithWidth(m.width - 4).WithShowHelp(true)
+	m.state = "load"
+	return m.archiveForm.Init()
+}
+
+// startArchive starts the save or load filled in on the form
+func (m *ImageModel) startArchive(action string) tea.Cmd {
+	o := m.archiveOpts
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &imageArchive{
+		action: action,
+		layers: newPullProgress(),
+		bar:    progress.New(progress.WithDefaultGradient(), progress.WithWidth(40), progress.WithoutPercentage()),
+		cancel: cancel,
+	}
+
+	if action == "save" {
+		a.path, _ = hostPath(o.path)
+		if o.gzip && !strings.HasSuffix(a.path, ".gz") {
+			a.path += ".gz"
+		}
+		// The uncompressed archive is about the size of the images
+		for _, img := range m.images {
+			for _, ref := range o.refs {
+				if ref == img.ID || slices.Contains(img.RepoTags, ref) {
+					a.total += img.Size
+					break
+				}
+			}
+		}
+		a.updates = m.runSave(ctx, o.refs, a.path, o.gzip)
+	} else {
+		a.path, _ = hostPath(o.loadPath)
+		if info, err := os.Stat(a.path); err == nil {
+			a.total = info.Size()
+		}
+		a.updates = m.runLoad(ctx, a.path)
+	}
+
+	m.archive = a
+	m.state = "archive"
+	return tea.Batch(waitForUpdate(a.updates), m.spin.Tick)
+}
+
+// archiveSender returns the send function of an archive goroutine and a
+// progress callback throttled to ten updates a second
+func archiveSender(ctx context.Context, updates chan<- tea.Msg) (func(tea.Msg) bool, func(int64)) {
+	send := func(msg tea.Msg) bool {
+		select {
+		case updates <- msg:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	var last time.Time
+	progress := func(n int64) {
+		if time.Since(last) >= 100*time.Millisecond {
+			last = time.Now()
+			send(ImageArchiveProgressMsg{Bytes: n})
+		}
+	}
+	return send, progress
+}
+
+// runSave writes the archive of the images to path. It is written to a
+// temporary file first, so a failed or cancelled save leaves nothing behind.
+func (m *ImageModel) runSave(ctx context.Context, refs []string, path string, compress bool) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+		send, progress := archiveSender(ctx, updates)
+
+		err := func() error {
+			reader, err := m.docker.Client.ImageSave(ctx, refs)
+			if err != nil {
+				return err
+			}
+			defer reader.Close()
+
+			tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(tmp.Name())
+			defer tmp.Close()
+
+			var out io.Writer = tmp
+			var gz *gzip.Writer
+			if compress {
+				gz = gzip.NewWriter(tmp)
+				out = gz
+			}
+			if _, err := io.Copy(out, layers.NewCountingReader(reader, progress)); err != nil {
+				return err
+			}
+			if gz != nil {
+				if err := gz.Close(); err != nil {
+					return err
+				}
+			}
+			if err := tmp.Close(); err != nil {
+				return err
+			}
+			// Temporary files are private; the archive gets the mode a
+			// plainly created file would have
+			if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+				return err
+			}
+			return os.Rename(tmp.Name(), path)
+		}()
+		send(ImageArchiveMsg{Error: err})
+	}()
+
+	return updates
+}
+
+// runLoad sends the archive at path to the daemon and streams its output
+func (m *ImageModel) runLoad(ctx context.Context, path string) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+		send, progress := archiveSender(ctx, updates)
+
+		err := func() error {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			resp, err := m.docker.Client.ImageLoad(ctx, layers.NewCountingReader(f, progress))
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			return decodePullStream(resp.Body, func(msg jsonmessage.JSONMessage) {
+				send(ImageLoadProgressMsg{Message: msg})
+			})
+		}()
+		send(ImageArchiveMsg{Error: err})
+	}()
+
+	return updates
+}
+
+// updateArchiveForm handles messages while the save or load form is shown
+func (m *ImageModel) updateArchiveForm(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+		m.state = "list"
+		return nil
+	}
+
+	newForm, cmd := m.archiveForm.Update(msg)
+	if form, ok := newForm.(*huh.Form); ok {
+		m.archiveForm = form
+	}
+	if m.archiveForm.State == huh.StateCompleted {
+		return m.startArchive(m.state)
+	}
+	return cmd
+}
+
+// updateArchive handles messages while a save or load is shown
+func (m *ImageModel) updateArchive(msg tea.Msg) tea.Cmd {
+	a := m.archive
+	switch msg := msg.(type) {
+	case ImageArchiveProgressMsg:
+		if a.done {
+			return nil
+		}
+		a.bytes = msg.Bytes
+		return waitForUpdate(a.updates)
+
+	case ImageLoadProgressMsg:
+		if a.done {
+			return nil
+		}
+		// The daemon ends with a "Loaded image: name" line per image
+		if loaded, ok := strings.CutPrefix(strings.TrimSpace(msg.Message.Stream), "Loaded image"); ok {
+			loaded = strings.TrimPrefix(strings.TrimPrefix(loaded, " ID"), ": ")
+			a.loaded = append(a.loaded, loaded)
+		} else if msg.Message.ID != "" {
+			a.layers.update(msg.Message)
+		}
+		return waitForUpdate(a.updates)
+
+	case ImageArchiveMsg:
+		if a.done {
+			return nil
+		}
+		a.cancel()
+		a.done = true
+		if info, err := os.Stat(a.path); err == nil && a.action == "save" {
+			a.bytes = info.Size()
+		}
+		if !errors.Is(msg.Error, context.Canceled) {
+			a.err = msg.Error
+		}
+		if a.err == nil && a.action == "load" {
+			return m.fetchImages()
+		}
+		return nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "backspace":
+			a.cancel()
+			m.archive = nil
+			m.state = "list"
+		case "enter":
+			if a.done {
+				m.archive = nil
+				m.state = "list"
+			}
+		}
+	}
+	return nil
+}
+
+// renderArchiveForm renders the save or load form
+func (m *ImageModel) renderArchiveForm() string {
+	title := "Save Images"
+	if m.state == "load" {
+		title = "Load Images"
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleTitle.Render(title),
+		"",
+		m.archiveForm.View(),
+		"",
+		StyleHelp.Render("Enter: Next • Esc: Cancel"),
+	)
+}
+
+// renderArchive renders the progress and result of a save or load
+func (m *ImageModel) renderArchive() string {
+	a := m.archive
+	verb, title := "Saving to", "Save"
+	if a.action == "load" {
+		verb, title = "Loading", "Load"
+	}
+	sections := []string{StyleTitle.Render(fmt.Sprintf("%s: %s", title, a.path))}
+
+	if !a.done {
+		percent := 0.0
+		if a.total > 0 {
+			percent = min(float64(a.bytes)/float64(a.total), 1)
+		}
+		about := "of"
+		if a.action == "save" {
+			about = "of about"
+		}
+		sections = append(sections,
+			fmt.Sprintf("%s %s %s", m.spin.View(), verb, a.path),
+			fmt.Sprintf("%s %s %s %s", a.bar.ViewAs(percent),
+				formatter.FormatSize(float64(a.bytes)), about, formatter.FormatSize(float64(a.total))),
+		)
+	}
+	if !a.done && len(a.layers.layers) > 0 {
+		sections = append(sections, "", a.layers.View())
+	}
+
+	help := "Enter/esc: Back"
+	switch {
+	case !a.done:
+		help = "esc: Cancel"
+	case a.err != nil:
+		sections = append(sections, "", StyleError.Render(fmt.Sprintf("%s failed: %v", title, a.err)))
+	case a.action == "save":
+		sections = append(sections, "", StyleSuccess.Render(fmt.Sprintf("Saved %s (%s)", a.path, formatter.FormatSize(float64(a.bytes)))))
+	default:
+		lines := []string{StyleSuccess.Render(fmt.Sprintf("Loaded %d images", len(a.loaded)))}
+		for _, name := range a.loaded {
+			lines = append(lines, "  "+name)
+		}
+		sections = append(sections, "", strings.Join(lines, "\n"))
+	}
+
+	sections = append(sections, "", StyleHelp.Render(help))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
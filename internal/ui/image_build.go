@@ -0,0 +1,434 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/buildcontext"
+	"github.com/Gostatsog/dockerNav/internal/registryauth"
+	"github.com/Gostatsog/dockerNav/internal/shellwords"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// buildStepPattern matches the header the builder prints for every
+// Dockerfile instruction, e.g. "Step 2/5 : RUN make"
+var buildStepPattern = regexp.MustCompile(`^Step \d+/\d+ : `)
+
+// ImageBuildProgressMsg carries a message of the build output stream
+type ImageBuildProgressMsg struct {
+	Message jsonmessage.JSONMessage
+}
+
+// ImageBuildMsg reports the end of a build with the ID of the new image
+type ImageBuildMsg struct {
+	ImageID string
+	Error   error
+}
+
+// buildOptions are the values of the build form, kept between builds
+type buildOptions struct {
+	contextDir string
+	dockerfile string
+	tags       string
+	buildArgs  string
+	target     string
+	noCache    bool
+	pull       bool
+}
+
+// imageBuild is the state of a running or finished build
+type imageBuild struct {
+	title     string
+	output    strings.Builder
+	pull      *pullProgress // base image pull of the current step
+	log       viewport.Model
+	updates   <-chan tea.Msg
+	cancel    context.CancelFunc
+	done      bool
+	cancelled bool
+	imageID   string
+	err       error
+}
+
+// parseTags splits the tags of the build form
+func parseTags(value string) ([]string, error) {
+	tags := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, tag := range tags {
+		if err := validateTag(tag); err != nil {
+			return nil, fmt.Errorf("%s: %w", tag, err)
+		}
+	}
+	return tags, nil
+}
+
+// parseBuildArgs parses shell-quoted KEY=VALUE words. A bare KEY takes its
+// value from the environment like docker build --build-arg KEY, and is left
+// unset when the variable is not set.
+func parseBuildArgs(value string) (map[string]*string, error) {
+	words, err := shellwords.Split(value)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make(map[string]*string, len(words))
+	for _, word := range words {
+		key, v, hasValue := strings.Cut(word, "=")
+		if key == "" {
+			return nil, fmt.Errorf("%q is not KEY=VALUE", word)
+		}
+		if !hasValue {
+			env, ok := os.LookupEnv(key)
+			if !ok {
+				args[key] = nil
+				continue
+			}
+			v = env
+		}
+		args[key] = &v
+	}
+	return args, nil
+}
+
+// validateDir checks that a path is an existing directory
+func validateDir(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("a directory is required")
+	}
+	info, err := os.Stat(value)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", value)
+	}
+	return nil
+}
+
+// startBuildForm shows the build form
+func (m *ImageModel) startBuildForm() tea.Cmd {
+	if m.buildOpts.contextDir == "" {
+		m.buildOpts.contextDir = "."
+	}
+	if m.buildOpts.dockerfile == "" {
+		m.buildOpts.dockerfile = "Dockerfile"
+	}
+	m.buildErr = ""
+	m.initBuildForm()
+	m.state = "build"
+	return m.buildForm.Init()
+}
+
+// initBuildForm builds the build form around the current values
+func (m *ImageModel) initBuildForm() {
+	o := &m.buildOpts
+	m.buildForm = huh.NewForm(huh.NewGroup(
+		huh.NewInput().
+			Title("Context Directory").
+			Description("Directory sent to the daemon; its .dockerignore is honoured").
+			Value(&o.contextDir).
+			Validate(validateDir),
+
+		huh.NewInput().
+			Title("Dockerfile").
+			Description("Relative to the context directory, or an absolute path. The classic builder is used, so RUN --mount and heredocs are not supported").
+			Placeholder("Dockerfile").
+			Value(&o.dockerfile),
+
+		huh.NewInput().
+			Title("Tags").
+			Description("repository:tag, separated by spaces").
+			Placeholder("myapp:latest").
+			Value(&o.tags).
+			Validate(validateWith(parseTags)),
+
+		huh.NewInput().
+			Title("Build Arguments").
+			Description("KEY=VALUE, separated by spaces; a bare KEY takes its value from the environment").
+			Value(&o.buildArgs).
+			Validate(validateWith(parseBuildArgs)),
+
+		huh.NewInput().
+			Title("Target Stage").
+			Description("Stage of a multi-stage Dockerfile to stop at; empty builds the last one").
+			Value(&o.target),
+
+		huh.NewConfirm().
+			Title("Build without cache?").
+			Value(&o.noCache),
+
+		huh.NewConfirm().
+			Title("Always pull base images?").
+			Value(&o.pull),
+	)).WithWidth(m.width - 4).WithShowHelp(true)
+}
+
+// startBuild packs the context and starts the build
+func (m *ImageModel) startBuild() tea.Cmd {
+	o := m.buildOpts
+	tags, err := parseTags(o.tags)
+	if err != nil {
+		return m.showBuildFormError(err)
+	}
+	args, err := parseBuildArgs(o.buildArgs)
+	if err != nil {
+		return m.showBuildFormError(err)
+	}
+	// Base images may come from any registry, so every stored login is sent
+	auths, err := registryauth.All()
+	if err != nil {
+		return m.showBuildFormError(err)
+	}
+	buildCtx, dockerfile, err := buildcontext.Tar(o.contextDir, o.dockerfile)
+	if err != nil {
+		return m.showBuildFormError(err)
+	}
+
+	options := types.ImageBuildOptions{
+		Tags:        tags,
+		Dockerfile:  dockerfile,
+		BuildArgs:   args,
+		Target:      strings.TrimSpace(o.target),
+		NoCache:     o.noCache,
+		PullParent:  o.pull,
+		Remove:      true,
+		ForceRemove: true,
+		AuthConfigs: auths,
+		Version:     types.BuilderV1,
+	}
+
+	title := o.contextDir
+	if len(tags) > 0 {
+		title = strings.Join(tags, ", ")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.build = &imageBuild{
+		title:  title,
+		log:    viewport.New(0, 0),
+		cancel: cancel,
+	}
+	m.build.updates = m.runBuild(ctx, buildCtx, options)
+	m.state = "building"
+	m.resizeBuildLog()
+	return tea.Batch(waitForUpdate(m.build.updates), m.spin.Tick)
+}
+
+// showBuildFormError shows the build form again with an error
+func (m *ImageModel) showBuildFormError(err error) tea.Cmd {
+	m.buildErr = err.Error()
+	m.initBuildForm()
+	return m.buildForm.Init()
+}
+
+// runBuild sends the context to the daemon and streams the build output.
+// Messages are sent on the returned channel, which is closed when the build
+// ends or ctx is done.
+func (m *ImageModel) runBuild(ctx context.Context, buildCtx io.ReadCloser, options types.ImageBuildOptions) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+		defer buildCtx.Close()
+
+		send := func(msg tea.Msg) bool {
+			select {
+			case updates <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var result types.BuildResult
+		err := func() error {
+			resp, err := m.docker.Client.ImageBuild(ctx, buildCtx, options)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			return decodePullStream(resp.Body, func(msg jsonmessage.JSONMessage) {
+				// The ID of the new image comes as auxiliary data
+				if msg.Aux != nil {
+					json.Unmarshal(*msg.Aux, &result)
+					return
+				}
+				send(ImageBuildProgressMsg{Message: msg})
+			})
+		}()
+		send(ImageBuildMsg{ImageID: result.ID, Error: err})
+	}()
+
+	return updates
+}
+
+// resizeBuildLog fits the build output below the title
+func (m *ImageModel) resizeBuildLog() {
+	if m.build == nil {
+		return
+	}
+	m.build.log.Width = m.width - 4
+	m.build.log.Height = max(m.height-12, 5)
+	m.refreshBuildLog()
+}
+
+// refreshBuildLog renders the output so far with the step headers
+// highlighted, following the end of the output
+func (m *ImageModel) refreshBuildLog() {
+	b := m.build
+	atBottom := b.log.AtBottom() || b.log.TotalLineCount() <= b.log.Height
+
+	lines := strings.Split(strings.TrimRight(b.output.String(), "\n"), "\n")
+	for i, line := range lines {
+		if buildStepPattern.MatchString(line) {
+			lines[i] = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render(line)
+		}
+	}
+	b.log.SetContent(strings.Join(lines, "\n"))
+	if atBottom {
+		b.log.GotoBottom()
+	}
+}
+
+// updateBuildForm handles messages while the build form is shown
+func (m *ImageModel) updateBuildForm(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+		m.state = "list"
+		return nil
+	}
+
+	newForm, cmd := m.buildForm.Update(msg)
+	if form, ok := newForm.(*huh.Form); ok {
+		m.buildForm = form
+	}
+	if m.buildForm.State == huh.StateCompleted {
+		return m.startBuild()
+	}
+	return cmd
+}
+
+// updateBuild handles messages while the build output is shown
+func (m *ImageModel) updateBuild(msg tea.Msg) tea.Cmd {
+	b := m.build
+	switch msg := msg.(type) {
+	case ImageBuildProgressMsg:
+		if b.done {
+			return nil
+		}
+		if msg.Message.Stream != "" {
+			// A new step ends the pull of the previous one
+			if b.pull != nil && b.pull.status != "" {
+				b.output.WriteString(b.pull.status + "\n")
+			}
+			b.pull = nil
+			b.output.WriteString(msg.Message.Stream)
+		} else if msg.Message.Status != "" {
+			if b.pull == nil {
+				b.pull = newPullProgress()
+			}
+			b.pull.update(msg.Message)
+		}
+		m.refreshBuildLog()
+		return waitForUpdate(b.updates)
+
+	case ImageBuildMsg:
+		if b.done {
+			return nil
+		}
+		b.cancel()
+		b.done = true
+		b.pull = nil
+		b.imageID = msg.ImageID
+		b.err = msg.Error
+		if errors.Is(b.err, context.Canceled) {
+			b.err = nil
+			b.cancelled = true
+		}
+		if b.err == nil && !b.cancelled {
+			// Select the new image once the list is refreshed
+			m.selectImageID = b.imageID
+			return m.fetchImages()
+		}
+		return nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "backspace":
+			if !b.done {
+				b.cancel()
+				b.done = true
+				b.pull = nil
+				b.cancelled = true
+				return nil
+			}
+			m.build = nil
+			m.state = "list"
+			return nil
+		case "enter":
+			if b.done {
+				m.build = nil
+				m.state = "list"
+			}
+			return nil
+		}
+		var cmd tea.Cmd
+		b.log, cmd = b.log.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// renderBuildForm renders the build form
+func (m *ImageModel) renderBuildForm() string {
+	sections := []string{
+		StyleTitle.Render("Build Image"),
+		"",
+		m.buildForm.View(),
+	}
+	if m.buildErr != "" {
+		sections = append(sections, "", StyleError.Render(m.buildErr))
+	}
+	sections = append(sections, "", StyleHelp.Render("Enter: Next • Esc: Cancel"))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// renderBuild renders the build output and its result
+func (m *ImageModel) renderBuild() string {
+	b := m.build
+	sections := []string{
+		StyleTitle.Render(fmt.Sprintf("Build: %s", b.title)),
+		b.log.View(),
+	}
+	if b.pull != nil && len(b.pull.layers) > 0 {
+		sections = append(sections, "", b.pull.View())
+	}
+
+	help := "↑/↓: Scroll • esc: Back"
+	switch {
+	case !b.done:
+		sections = append(sections, "", fmt.Sprintf("%s Building...", m.spin.View()))
+		help = "↑/↓: Scroll • esc: Cancel build"
+	case b.cancelled:
+		sections = append(sections, "", StyleWarning.Render("Build cancelled"))
+	case b.err != nil:
+		sections = append(sections, "", StyleError.Render(fmt.Sprintf("Build failed: %v", b.err)))
+	default:
+		sections = append(sections, "", StyleSuccess.Render(fmt.Sprintf("Built %s", shortImageID(b.imageID))))
+		help = "↑/↓: Scroll • Enter: Show in list • esc: Back"
+	}
+
+	sections = append(sections, "", StyleHelp.Render(help))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
@@ -0,0 +1,314 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/internal/layers"
+	"github.com/Gostatsog/dockerNav/pkg/formatter"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxExplorerLayerRows is how many layers are listed above the file tree
+const maxExplorerLayerRows = 8
+
+// ImageExploreProgressMsg carries how much of the image archive was read
+type ImageExploreProgressMsg struct {
+	Read int64
+}
+
+// ImageExploreMsg carries the analysed layers of an image
+type ImageExploreMsg struct {
+	Image *layers.Image
+	Error error
+}
+
+// layerExplorer is the state of the layer content explorer
+type layerExplorer struct {
+	title      string
+	size       int64 // image size, to estimate how much is left to read
+	read       int64
+	image      *layers.Image
+	err        error
+	cursor     int
+	showWasted bool
+	bar        progress.Model
+	tree       viewport.Model
+	updates    <-chan tea.Msg
+	cancel     context.CancelFunc
+}
+
+// exploreImage starts reading the layers of an image
+func (m *ImageModel) exploreImage(item ImageItem) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.explorer = &layerExplorer{
+		title:  item.title,
+		size:   item.image.Size,
+		bar:    progress.New(progress.WithDefaultGradient(), progress.WithWidth(40), progress.WithoutPercentage()),
+		tree:   viewport.New(0, 0),
+		cancel: cancel,
+	}
+	m.explorer.updates = m.runExplore(ctx, item.image.ID)
+	m.state = "explore"
+	m.resizeExplorer()
+	return tea.Batch(waitForUpdate(m.explorer.updates), m.spin.Tick)
+}
+
+// runExplore streams the image archive from the daemon and analyses its
+// layers. Messages are sent on the returned channel, which is closed when
+// the analysis ends or ctx is done.
+func (m *ImageModel) runExplore(ctx context.Context, imageID string) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+
+		send := func(msg tea.Msg) bool {
+			select {
+			case updates <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		img, err := func() (*layers.Image, error) {
+			reader, err := m.docker.Client.ImageSave(ctx, []string{imageID})
+			if err != nil {
+				return nil, err
+			}
+			defer reader.Close()
+
+			var last time.Time
+			return layers.Read(reader, func(read int64) {
+				if time.Since(last) >= 100*time.Millisecond {
+					last = time.Now()
+					send(ImageExploreProgressMsg{Read: read})
+				}
+			})
+		}()
+		send(ImageExploreMsg{Image: img, Error: err})
+	}()
+
+	return updates
+}
+
+// stopExplore cancels reading the image archive and leaves the explorer
+func (m *ImageModel) stopExplore() {
+	if m.explorer != nil {
+		m.explorer.cancel()
+		m.explorer = nil
+	}
+	m.state = "list"
+}
+
+// resizeExplorer fits the file tree below the layer list
+func (m *ImageModel) resizeExplorer() {
+	e := m.explorer
+	if e == nil {
+		return
+	}
+	e.tree.Width = m.width - 4
+	e.tree.Height = max(m.height-maxExplorerLayerRows-14, 5)
+}
+
+// updateExplore handles messages while the explorer is shown
+func (m *ImageModel) updateExplore(msg tea.Msg) tea.Cmd {
+	e := m.explorer
+	switch msg := msg.(type) {
+	case ImageExploreProgressMsg:
+		e.read = msg.Read
+		return waitForUpdate(e.updates)
+
+	case ImageExploreMsg:
+		e.cancel()
+		e.image = msg.Image
+		e.err = msg.Error
+		m.refreshExplorerTree()
+		return nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "backspace":
+			m.stopExplore()
+			return nil
+		case "up", "k":
+			if e.cursor > 0 {
+				e.cursor--
+				m.refreshExplorerTree()
+			}
+			return nil
+		case "down", "j":
+			if e.image != nil && e.cursor < len(e.image.Layers)-1 {
+				e.cursor++
+				m.refreshExplorerTree()
+			}
+			return nil
+		case "w":
+			e.showWasted = !e.showWasted
+			m.refreshExplorerTree()
+			return nil
+		}
+		var cmd tea.Cmd
+		e.tree, cmd = e.tree.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// refreshExplorerTree shows the changes of the selected layer, or the
+// wasted files, in the tree viewport
+func (m *ImageModel) refreshExplorerTree() {
+	e := m.explorer
+	if e.image == nil || len(e.image.Layers) == 0 {
+		return
+	}
+	if e.showWasted {
+		e.tree.SetContent(renderWastedFiles(e.image.WastedFiles))
+	} else {
+		e.tree.SetContent(renderChangeTree(e.image.Layers[e.cursor].Changes))
+	}
+	e.tree.GotoTop()
+}
+
+// changeStyle returns the marker and style of a change kind
+func changeStyle(kind layers.ChangeKind) (string, lipgloss.Style) {
+	switch kind {
+	case layers.Added:
+		return "+", StyleSuccess
+	case layers.Modified:
+		return "~", StyleWarning
+	}
+	return "-", StyleError
+}
+
+// renderChangeTree renders the changes of a layer as a file tree. The
+// directories leading to the changed paths are shown once.
+func renderChangeTree(changes []layers.Change) string {
+	if len(changes) == 0 {
+		return StyleSubtle.Render("This layer changes no files")
+	}
+
+	var lines []string
+	var open []string // directories of the previous line
+	for _, c := range changes {
+		parts := strings.Split(c.Path, "/")
+		dirs := parts[:len(parts)-1]
+
+		depth := 0
+		for depth < len(open) && depth < len(dirs) && open[depth] == dirs[depth] {
+			depth++
+		}
+		open = open[:depth]
+		for ; depth < len(dirs); depth++ {
+			lines = append(lines, strings.Repeat("  ", depth)+StyleSubtle.Render(dirs[depth]+"/"))
+			open = append(open, dirs[depth])
+		}
+
+		name := parts[len(parts)-1]
+		if c.Dir {
+			name += "/"
+		}
+		marker, style := changeStyle(c.Kind)
+		line := strings.Repeat("  ", len(dirs)) + style.Render(marker+" "+name)
+		if c.Size > 0 {
+			line += " " + StyleSubtle.Render(formatter.FormatSize(float64(c.Size)))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderWastedFiles renders the files whose lower copies are hidden
+func renderWastedFiles(files []layers.WastedFile) string {
+	if len(files) == 0 {
+		return StyleSuccess.Render("No file is overwritten or deleted by a later layer")
+	}
+
+	lines := []string{StyleSubtle.Render(fmt.Sprintf("%-11s %6s  %s", "WASTED", "COPIES", "PATH"))}
+	for _, f := range files {
+		lines = append(lines, fmt.Sprintf("%-11s %6d  %s", formatter.FormatSize(float64(f.Size)), f.Copies, f.Path))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderExplorer renders the layer list above the tree of the selected layer
+func (m *ImageModel) renderExplorer() string {
+	e := m.explorer
+	title := StyleTitle.Render(fmt.Sprintf("Layers: %s", e.title))
+
+	if e.err != nil {
+		return lipgloss.JoinVertical(lipgloss.Left,
+			title,
+			StyleError.Render(fmt.Sprintf("Error: %v", e.err)),
+			"",
+			StyleHelp.Render("esc: Back"),
+		)
+	}
+	if e.image == nil {
+		percent := 0.0
+		if e.size > 0 {
+			percent = min(float64(e.read)/float64(e.size), 1)
+		}
+		return lipgloss.JoinVertical(lipgloss.Left,
+			title,
+			fmt.Sprintf("%s Reading the image archive from the daemon", m.spin.View()),
+			fmt.Sprintf("%s %s of about %s", e.bar.ViewAs(percent),
+				formatter.FormatSize(float64(e.read)), formatter.FormatSize(float64(e.size))),
+			"",
+			StyleHelp.Render("esc: Cancel"),
+		)
+	}
+
+	img := e.image
+	summary := fmt.Sprintf("%d layers • %s of files • %s wasted in %d files • efficiency %.1f%%",
+		len(img.Layers),
+		formatter.FormatSize(float64(img.FileSize)),
+		formatter.FormatSize(float64(img.Wasted)),
+		len(img.WastedFiles),
+		img.Efficiency()*100,
+	)
+
+	// Keep the selected layer within the visible rows
+	first := max(0, min(e.cursor-maxExplorerLayerRows/2, len(img.Layers)-maxExplorerLayerRows))
+	last := min(first+maxExplorerLayerRows, len(img.Layers))
+	var rows []string
+	for i := first; i < last; i++ {
+		layer := img.Layers[i]
+		cursor := "  "
+		if i == e.cursor {
+			cursor = "> "
+		}
+		counts := fmt.Sprintf("+%d ~%d -%d", layer.Count(layers.Added), layer.Count(layers.Modified), layer.Count(layers.Deleted))
+		row := fmt.Sprintf("%s%3d  %-11s %-20s ", cursor, i+1, formatter.FormatSize(float64(layer.Size)), counts)
+		row += truncate(createdByInstruction(layer.Command), max(m.width-lipgloss.Width(row)-8, 10))
+		if i == e.cursor {
+			row = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render(row)
+		}
+		rows = append(rows, row)
+	}
+
+	heading := "Changes of the selected layer"
+	if e.showWasted {
+		heading = "Files overwritten or deleted by later layers"
+	} else if len(img.Layers) > 0 && img.Layers[e.cursor].Wasted > 0 {
+		heading += fmt.Sprintf(" (hides %s of lower layers)", formatter.FormatSize(float64(img.Layers[e.cursor].Wasted)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		summary,
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		StyleSubtle.Render(heading),
+		e.tree.View(),
+		"",
+		StyleHelp.Render("↑/↓: Layer • pgup/pgdown: Scroll • w: Wasted files • esc: Back"),
+	)
+}
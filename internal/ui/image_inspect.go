@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/pkg/formatter"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/image"
+)
+
+// largestLayers is how many of the biggest layers are highlighted
+const largestLayers = 3
+
+// ImageInspectMsg carries the details and layer history of an image
+type ImageInspectMsg struct {
+	Image   image.InspectResponse
+	History []image.HistoryResponseItem
+	Error   error
+}
+
+// inspectImage returns a command that fetches the details and history of an image
+func (m *ImageModel) inspectImage(imageID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		details, err := m.docker.Client.ImageInspect(ctx, imageID)
+		if err != nil {
+			return ImageInspectMsg{Error: err}
+		}
+		history, err := m.docker.Client.ImageHistory(ctx, imageID)
+		return ImageInspectMsg{
+			Image:   details,
+			History: history,
+			Error:   err,
+		}
+	}
+}
+
+// createdByInstruction shortens the command that created a layer to the
+// Dockerfile instruction it came from
+func createdByInstruction(createdBy string) string {
+	s := strings.Join(strings.Fields(createdBy), " ")
+	s = strings.TrimSuffix(s, " # buildkit")
+	switch {
+	case strings.HasPrefix(s, "RUN /bin/sh -c "):
+		return "RUN " + strings.TrimPrefix(s, "RUN /bin/sh -c ")
+	case strings.HasPrefix(s, "/bin/sh -c #(nop) "):
+		return strings.TrimPrefix(s, "/bin/sh -c #(nop) ")
+	case strings.HasPrefix(s, "/bin/sh -c "):
+		return "RUN " + strings.TrimPrefix(s, "/bin/sh -c ")
+	case s == "":
+		return "<missing>"
+	}
+	return s
+}
+
+// truncate shortens s to width characters
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width < 2 || len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// renderImageDetails formats the inspect metadata of an image
+func renderImageDetails(details image.InspectResponse) string {
+	var b strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			b.WriteString(fmt.Sprintf("%s: %s\n", label, value))
+		}
+	}
+	list := func(label string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		b.WriteString(fmt.Sprintf("\n%s:\n", label))
+		for _, v := range values {
+			b.WriteString(fmt.Sprintf("  %s\n", v))
+		}
+	}
+
+	line("ID", details.ID)
+	line("Tags", strings.Join(details.RepoTags, ", "))
+	if created, err := time.Parse(time.RFC3339Nano, details.Created); err == nil {
+		line("Created", fmt.Sprintf("%s (%s)", created.Format(time.RFC3339), formatter.FormatTime(created)))
+	}
+	platform := details.Os + "/" + details.Architecture
+	if details.Variant != "" {
+		platform += "/" + details.Variant
+	}
+	line("Platform", platform)
+	line("Size", formatter.FormatSize(float64(details.Size)))
+	line("Layers", fmt.Sprintf("%d", len(details.RootFS.Layers)))
+	line("Author", details.Author)
+	list("Digests", details.RepoDigests)
+
+	if cfg := details.Config; cfg != nil {
+		b.WriteString("\n")
+		line("Entrypoint", strings.Join(cfg.Entrypoint, " "))
+		line("Cmd", strings.Join(cfg.Cmd, " "))
+		line("Working Dir", cfg.WorkingDir)
+		line("User", cfg.User)
+		line("Stop Signal", cfg.StopSignal)
+
+		ports := make([]string, 0, len(cfg.ExposedPorts))
+		for port := range cfg.ExposedPorts {
+			ports = append(ports, string(port))
+		}
+		sort.Strings(ports)
+		line("Exposed Ports", strings.Join(ports, ", "))
+
+		list("Env", cfg.Env)
+
+		labels := make([]string, 0, len(cfg.Labels))
+		for k, v := range cfg.Labels {
+			labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(labels)
+		list("Labels", labels)
+	}
+	return b.String()
+}
+
+// renderImageHistory renders the layers as a table of size, age and the
+// instruction that created them, highlighting the largest layers
+func renderImageHistory(history []image.HistoryResponseItem, width int) string {
+	var total int64
+	bySize := make([]int, 0, len(history))
+	for i, layer := range history {
+		total += layer.Size
+		if layer.Size > 0 {
+			bySize = append(bySize, i)
+		}
+	}
+	sort.SliceStable(bySize, func(a, b int) bool {
+		return history[bySize[a]].Size > history[bySize[b]].Size
+	})
+	rank := map[int]int{}
+	for r, i := range bySize[:min(largestLayers, len(bySize))] {
+		rank[i] = r + 1
+	}
+
+	header := fmt.Sprintf("%-11s %6s  %-15s %s", "SIZE", "SHARE", "CREATED", "CREATED BY")
+	lines := []string{StyleSubtle.Render(header)}
+	commandWidth := width - lipgloss.Width(header) + len("CREATED BY")
+	for i, layer := range history {
+		share := 0.0
+		if total > 0 {
+			share = float64(layer.Size) / float64(total) * 100
+		}
+		line := fmt.Sprintf("%-11s %5.1f%%  %-15s %s",
+			formatter.FormatSize(float64(layer.Size)),
+			share,
+			formatter.FormatTime(time.Unix(layer.Created, 0)),
+			truncate(createdByInstruction(layer.CreatedBy), commandWidth),
+		)
+		switch rank[i] {
+		case 1:
+			line = StyleError.Render(line)
+		case 2, 3:
+			line = StyleWarning.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	summary := fmt.Sprintf("%d layers, %s in total", len(history), formatter.FormatSize(float64(total)))
+	if len(bySize) > 0 {
+		var largest int64
+		for _, i := range bySize[:min(largestLayers, len(bySize))] {
+			largest += history[i].Size
+		}
+		summary += fmt.Sprintf("; the %d largest layers hold %s (%.0f%%)",
+			min(largestLayers, len(bySize)), formatter.FormatSize(float64(largest)), float64(largest)/float64(total)*100)
+	}
+	lines = append(lines, "", summary)
+	return strings.Join(lines, "\n")
+}
+
+// renderInspect renders the inspect view with the details and layer table
+func (m *ImageModel) renderInspect() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleTitle.Render(fmt.Sprintf("Image Details: %s", m.inspectTitle)),
+		m.viewport.View(),
+		StyleFooter.Render("↑/↓: Scroll • Press esc to go back"),
+	)
+}
@@ -1,7 +1,6 @@
 package ui
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"strings"
@@ -15,25 +14,31 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 )
 
 // ImageListMsg carries image data after fetching
 type ImageListMsg struct {
-	Images []image.Summary
-	Error  error
+	Images     []image.Summary
+	Containers map[string][]Summary // containers by image ID
+	Error      error
 }
 
 // ImageActionMsg carries results of image actions
 type ImageActionMsg struct {
 	Action  string
 	ImageID string
+	Target  string // tag added or removed
 	Error   error
 }
 
 // ImagePullMsg carries results of pulling an image
 type ImagePullMsg struct {
+	PullID  int
+	Image   string
 	Success bool
 	Error   error
 }
@@ -57,8 +62,23 @@ func (i ImageItem) Description() string { return i.desc }
 // ImageKeyMap defines keybindings for image operations
 type ImageKeyMap struct {
 	Refresh key.Binding
+	Inspect key.Binding
+	Explore key.Binding
+	Containers key.Binding
 	Pull    key.Binding
+	Pulls   key.Binding
 	Remove  key.Binding
+	Tag     key.Binding
+	Untag   key.Binding
+	Push    key.Binding
+	Build   key.Binding
+	Save    key.Binding
+	Load    key.Binding
+	Login   key.Binding
+	Dangling key.Binding
+	Unused   key.Binding
+	Age      key.Binding
+	Prune    key.Binding
 	Back    key.Binding
 	MainMenu key.Binding
 }
@@ -70,14 +90,74 @@ func DefaultImageKeyMap() ImageKeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		Inspect: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "inspect"),
+		),
+		Explore: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "explore layers"),
+		),
+		Containers: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "containers"),
+		),
 		Pull: key.NewBinding(
 			key.WithKeys("p"),
 			key.WithHelp("p", "pull"),
 		),
+		Pulls: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "pull queue"),
+		),
 		Remove: key.NewBinding(
 			key.WithKeys("x"),
 			key.WithHelp("x", "remove"),
 		),
+		Tag: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "tag"),
+		),
+		Untag: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "remove tag"),
+		),
+		Push: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "push"),
+		),
+		Build: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "build"),
+		),
+		Save: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "save"),
+		),
+		Load: key.NewBinding(
+			key.WithKeys("l"),
+			key.WithHelp("l", "load"),
+		),
+		Login: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "registry login"),
+		),
+		Dangling: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "toggle dangling"),
+		),
+		Unused: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "toggle unused"),
+		),
+		Age: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "older than"),
+		),
+		Prune: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "prune listed"),
+		),
 		Back: key.NewBinding(
 			key.WithKeys("esc", "backspace"),
 			key.WithHelp("esc", "back"),
@@ -94,7 +174,7 @@ type ImageModel struct {
 	docker    *client.DockerClient
 	imageList list.Model
 	keyMap    ImageKeyMap
-	state     string // "list", "pull", "confirm"
+	state     string // "list", "inspect", "explore", "containers", "pull", "pulls", "push", "build", "building", "save", "load", "archive", "tag", "tags", "age", "prune", "login", "confirm"
 	width     int
 	height    int
 	spin      spinner.Model
@@ -103,8 +183,55 @@ type ImageModel struct {
 	selectedImage *image.Summary
 	confirmMsg   string
 	confirmAction string
+	confirmTarget string
 	loading    bool
 	error      error
+	pulls      []*pullJob
+	nextPullID int
+	pullCursor int
+	pullErr    string
+	notice     string
+	inspectTitle string
+	explorer     *layerExplorer
+	tagInput     textinput.Model
+	tagErr       string
+	tagCursor    int
+	tagAction    string
+	push         *imagePush
+	selectImageID string // image to select once the list is refreshed
+	images        []image.Summary      // last fetched images
+	usage         map[string][]Summary // containers by image ID
+	imageFilter   imageFilter
+	usageTitle    string
+	usageCursor   int
+
+	// Image filter age prompt and prune preview
+	ageInput     textinput.Model
+	ageErr       string
+	prune        []pruneCandidate
+	pruneSkipped []string
+	pruneCursor  int
+	pruning      bool
+	pruneResults []pruneResult
+
+	// Image build form and output
+	buildForm *huh.Form
+	buildOpts buildOptions
+	buildErr  string
+	build     *imageBuild
+
+	// Image save and load
+	archiveForm *huh.Form
+	archiveOpts archiveOptions
+	archive     *imageArchive
+
+	// Registry login form
+	loginForm     *huh.Form
+	loginServer   string
+	loginUser     string
+	loginPassword string
+	loginErr      string
+	loggingIn     bool
 }
 
 // NewImageModel creates a new image model
@@ -127,8 +254,23 @@ func NewImageModel(docker *client.DockerClient) *ImageModel {
 	imageList.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			keyMap.Refresh,
+			keyMap.Inspect,
+			keyMap.Explore,
+			keyMap.Containers,
 			keyMap.Pull,
+			keyMap.Pulls,
 			keyMap.Remove,
+			keyMap.Tag,
+			keyMap.Untag,
+			keyMap.Push,
+			keyMap.Build,
+			keyMap.Save,
+			keyMap.Load,
+			keyMap.Login,
+			keyMap.Dangling,
+			keyMap.Unused,
+			keyMap.Age,
+			keyMap.Prune,
 			keyMap.Back,
 			keyMap.MainMenu,
 		}
@@ -141,10 +283,18 @@ func NewImageModel(docker *client.DockerClient) *ImageModel {
 
 	// Setup text input for pulling images
 	ti := textinput.New()
-	ti.Placeholder = "image:tag (e.g., nginx:latest)"
-	ti.Width = 40
+	ti.Placeholder = "image:tag ... (e.g., nginx:latest redis:7)"
+	ti.Width = 50
 	ti.Focus()
 
+	tagInput := textinput.New()
+	tagInput.Placeholder = "repository:tag (e.g., registry.local:5000/app:1.0)"
+	tagInput.Width = 50
+
+	ageInput := textinput.New()
+	ageInput.Placeholder = "30d, 2w, 12h ... (empty to turn off)"
+	ageInput.Width = 50
+
 	// Set up viewport for scrollable content
 	vp := viewport.New(0, 0)
 	vp.Style = lipgloss.NewStyle().
@@ -159,6 +309,8 @@ func NewImageModel(docker *client.DockerClient) *ImageModel {
 		spin:      s,
 		viewport:  vp,
 		textInput: ti,
+		tagInput:  tagInput,
+		ageInput:  ageInput,
 		loading:   true,
 	}
 }
@@ -191,54 +343,137 @@ func (m *ImageModel) Init() tea.Cmd {
 func (m *ImageModel) fetchImages() tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		images, err := m.docker.Client.ImageList(ctx, image.ListOptions{})
+		images, err := m.docker.Client.ImageList(ctx, image.ListOptions{SharedSize: true})
+		if err != nil {
+			return ImageListMsg{Error: err}
+		}
+		containers, err := m.docker.Client.ContainerList(ctx, container.ListOptions{All: true})
 		return ImageListMsg{
-			Images: images,
-			Error:  err,
+			Images:     images,
+			Containers: imageContainers(containers),
+			Error:      err,
 		}
 	}
 }
 
-// pullImage returns a command that pulls an image
-func (m *ImageModel) pullImage(imageName string) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		reader, err := m.docker.Client.ImagePull(ctx, imageName, image.PullOptions{})
-		if err != nil {
-			return ImagePullMsg{Success: false, Error: err}
+// refreshImageItems rebuilds the list items from the last fetched images,
+// leaving out those the filter toggles hide
+func (m *ImageModel) refreshImageItems() tea.Cmd {
+	items := make([]list.Item, 0, len(m.images))
+	for _, img := range m.images {
+		if !m.imageFilter.Match(img, m.usage[img.ID]) {
+			continue
+		}
+		// Handle images with no repository/tag
+		name := "<none>:<none>"
+		if len(img.RepoTags) > 0 && img.RepoTags[0] != "<none>:<none>" {
+			name = img.RepoTags[0]
+		}
+		
+		// Format created time
+		createdTime := time.Unix(img.Created, 0)
+		created := formatter.FormatTime(createdTime)
+		
+		// Format size
+		size := formatter.FormatSize(float64(img.Size))
+		
+		desc := fmt.Sprintf("ID: %s • Created: %s • Size: %s",
+			img.ID[7:19],
+			created,
+			size,
+		)
+		if tags := imageTags(img.RepoTags); len(tags) > 1 {
+			desc += fmt.Sprintf(" • %d tags", len(tags))
 		}
-		defer reader.Close()
+		desc += " • " + usageSummary(m.usage[img.ID])
+		
+		items = append(items, ImageItem{
+			image: img,
+			title: name,
+			desc:  desc,
+		})
+	}
+	
+	m.imageList.Title = "Images"
+	if summary := m.imageFilter.Summary(); summary != "" {
+		m.imageList.Title = fmt.Sprintf("Images (%s)", summary)
+	}
+	return m.imageList.SetItems(items)
+}
 
-		// We need to read the response to complete the pull
-		buf := new(bytes.Buffer)
-		_, err = buf.ReadFrom(reader)
-		if err != nil {
-			return ImagePullMsg{Success: false, Error: err}
+// removeImage removes an image. The daemon refuses to remove an image with
+// several tags by ID unless forced, so the tags are removed one by one;
+// removing the last tag removes the image. As the daemon only refuses the
+// last tag of an image a container uses, such an image is refused before any
+// tag is touched.
+func (m *ImageModel) removeImage(ctx context.Context, imageID string, tags []string) error {
+	containers, err := m.docker.Client.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return err
+	}
+	var users []string
+	for _, c := range containers {
+		if c.ImageID == imageID {
+			users = append(users, containerName(containerSummaryToSummary(c)))
 		}
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("image %s is used by %s; remove those containers first", shortImageID(imageID), strings.Join(users, ", "))
+	}
 
-		return ImagePullMsg{Success: true, Error: nil}
+	if len(tags) <= 1 {
+		_, err := m.docker.Client.ImageRemove(ctx, imageID, image.RemoveOptions{})
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := m.docker.Client.ImageRemove(ctx, tag, image.RemoveOptions{}); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // performImageAction returns a command that performs an action on an image
-func (m *ImageModel) performImageAction(action string, imageID string) tea.Cmd {
+func (m *ImageModel) performImageAction(action string, imageID string, target string) tea.Cmd {
+	var tags []string
+	if m.selectedImage != nil && m.selectedImage.ID == imageID {
+		tags = imageTags(m.selectedImage.RepoTags)
+	}
+
 	return func() tea.Msg {
 		ctx := context.Background()
 		var err error
 		
 		switch action {
 		case "remove":
-			_, err = m.docker.Client.ImageRemove(ctx, imageID, image.RemoveOptions{})
+			err = m.removeImage(ctx, imageID, tags)
+		case "untag":
+			_, err = m.docker.Client.ImageRemove(ctx, target, image.RemoveOptions{})
 		}
 		
 		return ImageActionMsg{
 			Action:  action,
 			ImageID: imageID,
+			Target:  target,
 			Error:   err,
 		}
 	}
 }
 
+// CapturingInput reports whether a text input currently has focus
+func (m *ImageModel) CapturingInput() bool {
+	switch m.state {
+	case "pull", "tag", "age", "login", "build", "save", "load":
+		return true
+	case "prune":
+		// The images being removed stay listed until the prune reports back
+		return m.pruning
+	case "list":
+		return m.imageList.SettingFilter()
+	}
+	return false
+}
+
 // Update handles messages and updates the model
 func (m *ImageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
@@ -246,6 +481,21 @@ func (m *ImageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 		
 	case tea.KeyMsg:
+		if m.error != nil {
+			// The error replaces the view until it is dismissed
+			switch {
+			case key.Matches(msg, m.keyMap.Refresh):
+				m.error = nil
+				m.state = "list"
+				m.loading = true
+				return m, tea.Batch(m.fetchImages(), m.spin.Tick)
+			case key.Matches(msg, m.keyMap.Back):
+				m.error = nil
+				m.state = "list"
+			}
+			return m, nil
+		}
+
 		switch m.state {
 		case "list":
 			switch {
@@ -263,16 +513,103 @@ func (m *ImageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.loading = true
 				return m, tea.Batch(m.fetchImages(), m.spin.Tick)
 				
+			case key.Matches(msg, m.keyMap.Inspect):
+				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
+					m.inspectTitle = item.title
+					m.state = "inspect"
+					m.loading = true
+					return m, tea.Batch(m.inspectImage(item.image.ID), m.spin.Tick)
+				}
+
+			case key.Matches(msg, m.keyMap.Explore):
+				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
+					return m, m.exploreImage(item)
+				}
+
+			case key.Matches(msg, m.keyMap.Containers):
+				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
+					m.notice = ""
+					m.showImageContainers(item)
+					return m, nil
+				}
+
 			case key.Matches(msg, m.keyMap.Pull):
 				m.state = "pull"
+				m.pullErr = ""
 				m.textInput.Focus()
 				return m, nil
+
+			case key.Matches(msg, m.keyMap.Pulls):
+				m.state = "pulls"
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.Tag):
+				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
+					m.notice = ""
+					return m, m.startTag(item)
+				}
+
+			case key.Matches(msg, m.keyMap.Untag):
+				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
+					m.notice = ""
+					return m, m.chooseTag(item, "untag")
+				}
+
+			case key.Matches(msg, m.keyMap.Push):
+				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
+					m.notice = ""
+					return m, m.chooseTag(item, "push")
+				}
+
+			case key.Matches(msg, m.keyMap.Build):
+				m.notice = ""
+				return m, m.startBuildForm()
+
+			case key.Matches(msg, m.keyMap.Save):
+				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
+					m.notice = ""
+					return m, m.startSave(item)
+				}
+
+			case key.Matches(msg, m.keyMap.Load):
+				m.notice = ""
+				return m, m.startLoad()
+
+			case key.Matches(msg, m.keyMap.Login):
+				m.notice = ""
+				return m, m.startLogin()
+
+			case key.Matches(msg, m.keyMap.Dangling):
+				m.notice = ""
+				return m, m.toggleImageFilter("dangling")
+
+			case key.Matches(msg, m.keyMap.Unused):
+				m.notice = ""
+				return m, m.toggleImageFilter("unused")
+
+			case key.Matches(msg, m.keyMap.Age):
+				m.notice = ""
+				return m, m.startAge()
+
+			case key.Matches(msg, m.keyMap.Prune):
+				m.notice = ""
+				m.startPrune()
+				return m, nil
 				
 			case key.Matches(msg, m.keyMap.Remove):
 				if item, ok := m.imageList.SelectedItem().(ImageItem); ok {
 					m.selectedImage = &item.image
 					m.confirmMsg = fmt.Sprintf("Are you sure you want to remove image %s?", item.title)
+					if tags := imageTags(item.image.RepoTags); len(tags) > 1 {
+						m.confirmMsg = fmt.Sprintf("Image %s has %d tags: %s. Removing it removes every tag; use u in the list to remove a single tag.",
+							shortImageID(item.image.ID), len(tags), strings.Join(tags, ", "))
+					}
+					if warning := usageWarning(m.usage[item.image.ID]); warning != "" {
+						m.confirmMsg += "\n\n" + StyleWarning.Render(warning+
+							" The daemon refuses to remove an image a container uses, stopped or not; remove those containers first.")
+					}
 					m.confirmAction = "remove"
+					m.confirmTarget = ""
 					m.state = "confirm"
 					return m, nil
 				}
@@ -281,14 +618,18 @@ func (m *ImageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "pull":
 			switch msg.String() {
 			case "enter":
-				imageName := m.textInput.Value()
-				if imageName == "" {
+				refs, err := parsePullRefs(m.textInput.Value())
+				if err != nil {
+					m.pullErr = err.Error()
+					return m, nil
+				}
+				if len(refs) == 0 {
 					m.state = "list"
 					return m, nil
 				}
-				m.loading = true
-				m.state = "list"
-				return m, tea.Batch(m.pullImage(imageName), m.spin.Tick)
+				m.state = "pulls"
+				m.textInput.Reset()
+				return m, m.queuePulls(refs)
 				
 			case "esc":
 				m.state = "list"
@@ -300,11 +641,59 @@ func (m *ImageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.textInput, cmd = m.textInput.Update(msg)
 			return m, cmd
 			
+		case "inspect":
+			if key.Matches(msg, m.keyMap.Back) {
+				m.state = "list"
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+
+		case "explore":
+			return m, m.updateExplore(msg)
+
+		case "containers":
+			return m, m.updateImageContainers(msg)
+
+		case "pulls":
+			return m, m.updatePullQueue(msg)
+
+		case "login":
+			return m, m.updateLogin(msg)
+
+		case "tag":
+			return m, m.updateTag(msg)
+
+		case "tags":
+			return m, m.updateChooseTag(msg)
+
+		case "age":
+			return m, m.updateAge(msg)
+
+		case "prune":
+			return m, m.updatePrune(msg)
+
+		case "push":
+			return m, m.updatePush(msg)
+
+		case "build":
+			return m, m.updateBuildForm(msg)
+
+		case "building":
+			return m, m.updateBuild(msg)
+
+		case "save", "load":
+			return m, m.updateArchiveForm(msg)
+
+		case "archive":
+			return m, m.updateArchive(msg)
+
 		case "confirm":
 			switch msg.String() {
 			case "y", "Y":
 				if m.selectedImage != nil {
-					return m, m.performImageAction(m.confirmAction, m.selectedImage.ID)
+					return m, m.performImageAction(m.confirmAction, m.selectedImage.ID, m.confirmTarget)
 				}
 				m.state = "list"
 			case "n", "N", "esc":
@@ -335,6 +724,18 @@ func (m *ImageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update viewport dimensions
 		m.viewport.Width = m.width - 4
 		m.viewport.Height = m.height - headerHeight - footerHeight
+
+		if m.loginForm != nil {
+			m.loginForm = m.loginForm.WithWidth(m.width - 4)
+		}
+		if m.archiveForm != nil {
+			m.archiveForm = m.archiveForm.WithWidth(m.width - 4)
+		}
+		if m.buildForm != nil {
+			m.buildForm = m.buildForm.WithWidth(m.width - 4)
+		}
+		m.resizeExplorer()
+		m.resizeBuildLog()
 		
 		return m, nil
 
@@ -350,64 +751,100 @@ func (m *ImageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		items := make([]list.Item, 0, len(msg.Images))
-		for _, img := range msg.Images {
-			// Handle images with no repository/tag
-			name := "<none>:<none>"
-			if len(img.RepoTags) > 0 && img.RepoTags[0] != "<none>:<none>" {
-				name = img.RepoTags[0]
+		m.images = msg.Images
+		m.usage = msg.Containers
+		cmd := m.refreshImageItems()
+		if m.selectImageID != "" {
+			for i, item := range m.imageList.Items() {
+				if item.(ImageItem).image.ID == m.selectImageID {
+					m.imageList.Select(i)
+					break
+				}
 			}
-			
-			// Format created time
-			createdTime := time.Unix(img.Created, 0)
-			created := formatter.FormatTime(createdTime)
-			
-			// Format size
-			size := formatter.FormatSize(float64(img.Size))
-			
-			desc := fmt.Sprintf("ID: %s • Created: %s • Size: %s",
-				img.ID[7:19],
-				created,
-				size,
-			)
-			
-			items = append(items, ImageItem{
-				image: img,
-				title: name,
-				desc:  desc,
-			})
+			m.selectImageID = ""
 		}
-		
-		cmd := m.imageList.SetItems(items)
 		return m, cmd
 		
-	case ImagePullMsg:
+	case ImagePullProgressMsg, ImagePullMsg:
+		return m, m.updatePull(msg)
+
+	case RegistryLoginMsg:
+		return m, m.updateLogin(msg)
+
+	case ImageExploreProgressMsg, ImageExploreMsg:
+		if m.explorer == nil {
+			return m, nil
+		}
+		return m, m.updateExplore(msg)
+
+	case ImagePushProgressMsg, ImagePushMsg:
+		if m.push == nil {
+			return m, nil
+		}
+		return m, m.updatePush(msg)
+
+	case ImageBuildProgressMsg, ImageBuildMsg:
+		if m.build == nil {
+			return m, nil
+		}
+		return m, m.updateBuild(msg)
+
+	case ImageArchiveProgressMsg, ImageLoadProgressMsg, ImageArchiveMsg:
+		if m.archive == nil {
+			return m, nil
+		}
+		return m, m.updateArchive(msg)
+
+	case ImagePruneMsg:
+		return m, m.updatePrune(msg)
+
+	case ImageInspectMsg:
 		m.loading = false
 		if msg.Error != nil {
 			m.error = msg.Error
+			m.state = "list"
 			return m, nil
 		}
-		
-		// Refresh image list after successful pull
-		return m, m.fetchImages()
+
+		content := renderImageDetails(msg.Image) +
+			"\nLayer History:\n" +
+			renderImageHistory(msg.History, m.viewport.Width-2)
+		m.viewport.SetContent(content)
+		m.viewport.GotoTop()
+		return m, nil
 		
 	case ImageActionMsg:
 		m.loading = false
 		if msg.Error != nil {
 			m.error = msg.Error
+			m.state = "list"
 			return m, nil
 		}
 		
+		switch msg.Action {
+		case "tag":
+			m.notice = fmt.Sprintf("Tagged %s as %s", shortImageID(msg.ImageID), msg.Target)
+		case "untag":
+			m.notice = fmt.Sprintf("Removed tag %s", msg.Target)
+		}
+		
 		// Refresh image list after successful action
 		m.state = "list"
 		return m, m.fetchImages()
 	}
 
 	// Update list in list state
-	if m.state == "list" {
+	switch m.state {
+	case "list":
 		var cmd tea.Cmd
 		m.imageList, cmd = m.imageList.Update(msg)
 		cmds = append(cmds, cmd)
+	case "login":
+		cmds = append(cmds, m.updateLogin(msg))
+	case "build":
+		cmds = append(cmds, m.updateBuildForm(msg))
+	case "save", "load":
+		cmds = append(cmds, m.updateArchiveForm(msg))
 	}
 
 	return m, tea.Batch(cmds...)
@@ -439,6 +876,37 @@ func (m *ImageModel) View() string {
 		)
 	}
 
+	switch m.state {
+	case "pulls":
+		return StyleMainLayout.Render(m.renderPullQueue())
+	case "login":
+		return StyleMainLayout.Render(m.renderLogin())
+	case "inspect":
+		return StyleMainLayout.Render(m.renderInspect())
+	case "explore":
+		return StyleMainLayout.Render(m.renderExplorer())
+	case "containers":
+		return StyleMainLayout.Render(m.renderImageContainers())
+	case "tag":
+		return StyleMainLayout.Render(m.renderTag())
+	case "tags":
+		return StyleMainLayout.Render(m.renderChooseTag())
+	case "age":
+		return StyleMainLayout.Render(m.renderAge())
+	case "prune":
+		return StyleMainLayout.Render(m.renderPrune())
+	case "push":
+		return StyleMainLayout.Render(m.renderPush())
+	case "build":
+		return StyleMainLayout.Render(m.renderBuildForm())
+	case "building":
+		return StyleMainLayout.Render(m.renderBuild())
+	case "save", "load":
+		return StyleMainLayout.Render(m.renderArchiveForm())
+	case "archive":
+		return StyleMainLayout.Render(m.renderArchive())
+	}
+
 	var content string
 	switch m.state {
 	case "list":
@@ -462,12 +930,15 @@ func (m *ImageModel) View() string {
 	case "pull":
 		inputBox := StyleInfoBox.Render(
 			lipgloss.JoinVertical(lipgloss.Left,
-				"Enter image to pull:",
+				"Enter images to pull, separated by spaces:",
 				m.textInput.View(),
 				"",
 				"Press Enter to pull or Esc to cancel",
 			),
 		)
+		if m.pullErr != "" {
+			inputBox = lipgloss.JoinVertical(lipgloss.Left, inputBox, StyleError.Render(m.pullErr))
+		}
 		
 		content = lipgloss.JoinVertical(lipgloss.Left,
 			StyleTitle.Render("Pull Image"),
@@ -478,7 +949,7 @@ func (m *ImageModel) View() string {
 	case "confirm":
 		confirmBox := StyleInfoBox.Render(
 			lipgloss.JoinVertical(lipgloss.Left,
-				m.confirmMsg,
+				lipgloss.NewStyle().Width(max(min(m.width-8, 90), 40)).Render(m.confirmMsg),
 				"",
 				"Press (y)es to confirm or (n)o to cancel",
 			),
@@ -493,8 +964,17 @@ func (m *ImageModel) View() string {
 
 	if m.state == "list" {
 		helpText := StyleHelp.Render(
-			"r: Refresh • p: Pull • x: Remove • esc: Back • m: Main Menu",
+			"r: Refresh • i: Inspect • e: Layers • c: Containers • p: Pull • P: Pull queue • t: Tag • u: Untag • U: Push • b: Build • s: Save • l: Load • x: Remove • L: Login • d: Dangling • n: Unused • o: Older than • X: Prune listed • esc: Back • m: Main Menu",
 		)
+		if m.notice != "" {
+			helpText = lipgloss.JoinVertical(lipgloss.Left, StyleSuccess.Render(m.notice), helpText)
+		}
+		if active := m.activePulls(); active > 0 {
+			helpText = lipgloss.JoinVertical(lipgloss.Left,
+				StyleWarning.Render(fmt.Sprintf("%d pulls in progress, press P to follow them", active)),
+				helpText,
+			)
+		}
 		content = lipgloss.JoinVertical(lipgloss.Left, content, "", helpText)
 	}
 
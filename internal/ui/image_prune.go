@@ -0,0 +1,381 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/pkg/formatter"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/image"
+)
+
+// agePattern matches ages given in days or weeks, which time.ParseDuration
+// does not accept
+var agePattern = regexp.MustCompile(`^(\d+)\s*([dw])$`)
+
+// imageFilter holds the filter toggles of the image list. Toggles that are
+// on must all match.
+type imageFilter struct {
+	dangling  bool          // only images without a tag
+	unused    bool          // only images no container uses
+	olderThan time.Duration // only images created longer ago, when not zero
+}
+
+// Active reports whether any toggle is on
+func (f imageFilter) Active() bool {
+	return f.dangling || f.unused || f.olderThan > 0
+}
+
+// Match reports whether an image passes the toggles
+func (f imageFilter) Match(img image.Summary, containers []Summary) bool {
+	if f.dangling && len(imageTags(img.RepoTags)) > 0 {
+		return false
+	}
+	if f.unused && len(containers) > 0 {
+		return false
+	}
+	if f.olderThan > 0 && time.Since(time.Unix(img.Created, 0)) < f.olderThan {
+		return false
+	}
+	return true
+}
+
+// Summary describes the toggles that are on for the list title
+func (f imageFilter) Summary() string {
+	var parts []string
+	if f.dangling {
+		parts = append(parts, "dangling")
+	}
+	if f.unused {
+		parts = append(parts, "unused")
+	}
+	if f.olderThan > 0 {
+		parts = append(parts, "older than "+formatAge(f.olderThan))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// parseAge parses an age such as 30d, 2w, 12h or 90m
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if match := agePattern.FindStringSubmatch(value); match != nil {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, err
+		}
+		day := 24 * time.Hour
+		if match[2] == "w" {
+			return time.Duration(n) * 7 * day, nil
+		}
+		return time.Duration(n) * day, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an age such as 30d, 2w or 12h", value)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("the age must be positive")
+	}
+	return d, nil
+}
+
+// formatAge renders an age in the largest whole unit
+func formatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d%(7*day) == 0:
+		return fmt.Sprintf("%dw", d/(7*day))
+	case d%day == 0:
+		return fmt.Sprintf("%dd", d/day)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
+
+// toggleImageFilter flips the dangling or unused toggle
+func (m *ImageModel) toggleImageFilter(toggle string) tea.Cmd {
+	switch toggle {
+	case "dangling":
+		m.imageFilter.dangling = !m.imageFilter.dangling
+	case "unused":
+		m.imageFilter.unused = !m.imageFilter.unused
+	}
+	return m.refreshImageItems()
+}
+
+// startAge prompts for the minimum age of the listed images
+func (m *ImageModel) startAge() tea.Cmd {
+	m.ageErr = ""
+	m.ageInput.Reset()
+	if m.imageFilter.olderThan > 0 {
+		m.ageInput.SetValue(formatAge(m.imageFilter.olderThan))
+	}
+	m.state = "age"
+	return m.ageInput.Focus()
+}
+
+// updateAge handles keys in the age prompt. An empty age turns the toggle off.
+func (m *ImageModel) updateAge(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		var age time.Duration
+		if value := strings.TrimSpace(m.ageInput.Value()); value != "" {
+			var err error
+			if age, err = parseAge(value); err != nil {
+				m.ageErr = err.Error()
+				return nil
+			}
+		}
+		m.imageFilter.olderThan = age
+		m.state = "list"
+		return m.refreshImageItems()
+
+	case "esc":
+		m.state = "list"
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.ageInput, cmd = m.ageInput.Update(msg)
+	m.ageErr = ""
+	return cmd
+}
+
+// renderAge renders the age prompt
+func (m *ImageModel) renderAge() string {
+	lines := []string{
+		"Only list images created longer ago than:",
+		m.ageInput.View(),
+	}
+	if m.ageErr != "" {
+		lines = append(lines, "", StyleError.Render(m.ageErr))
+	}
+	lines = append(lines, "", "Press Enter to apply or Esc to cancel")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleTitle.Render("Filter Images by Age"),
+		"",
+		StyleInfoBox.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// pruneCandidate is an image in the prune preview
+type pruneCandidate struct {
+	image    image.Summary
+	title    string
+	selected bool
+}
+
+// pruneResult is the outcome of removing one image of a prune
+type pruneResult struct {
+	Title string
+	Size  int64
+	Error error
+}
+
+// ImagePruneMsg carries the outcome of a prune
+type ImagePruneMsg struct {
+	Results []pruneResult
+}
+
+// uniqueSize returns the bytes only an image uses, which removing it frees.
+// The daemon reports -1 when it did not compute the shared size.
+func uniqueSize(img image.Summary) int64 {
+	if img.SharedSize < 0 {
+		return img.Size
+	}
+	return img.Size - img.SharedSize
+}
+
+// startPrune previews the removal of the images the list shows, so a typed
+// filter narrows the toggles further. Images a container uses cannot be
+// removed and are listed apart.
+func (m *ImageModel) startPrune() {
+	if !m.imageFilter.Active() {
+		m.notice = "Turn on a filter first (d: dangling, n: unused, o: older than) to choose the images to prune"
+		return
+	}
+
+	m.prune = nil
+	m.pruneSkipped = nil
+	for _, it := range m.imageList.VisibleItems() {
+		item := it.(ImageItem)
+		if containers := m.usage[item.image.ID]; len(containers) > 0 {
+			m.pruneSkipped = append(m.pruneSkipped, fmt.Sprintf("%s: %s", item.title, usageSummary(containers)))
+			continue
+		}
+		m.prune = append(m.prune, pruneCandidate{image: item.image, title: item.title, selected: true})
+	}
+	if len(m.prune) == 0 {
+		m.notice = "No image to prune: every listed image is used by a container"
+		if len(m.pruneSkipped) == 0 {
+			m.notice = "No image matches the filters"
+		}
+		return
+	}
+	m.pruneCursor = 0
+	m.pruneResults = nil
+	m.pruning = false
+	m.state = "prune"
+}
+
+// pruneSelection returns the checked images and the space removing them frees
+func (m *ImageModel) pruneSelection() ([]pruneCandidate, int64) {
+	var selected []pruneCandidate
+	var size int64
+	for _, c := range m.prune {
+		if c.selected {
+			selected = append(selected, c)
+			size += uniqueSize(c.image)
+		}
+	}
+	return selected, size
+}
+
+// pruneImages removes exactly the confirmed images, one at a time
+func (m *ImageModel) pruneImages(targets []pruneCandidate) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		results := make([]pruneResult, 0, len(targets))
+		for _, t := range targets {
+			err := m.removeImage(ctx, t.image.ID, imageTags(t.image.RepoTags))
+			results = append(results, pruneResult{Title: t.title, Size: uniqueSize(t.image), Error: err})
+		}
+		return ImagePruneMsg{Results: results}
+	}
+}
+
+// updatePrune handles messages while the prune preview or its results are
+// shown
+func (m *ImageModel) updatePrune(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case ImagePruneMsg:
+		m.pruning = false
+		m.pruneResults = msg.Results
+		return m.fetchImages()
+
+	case tea.KeyMsg:
+		if m.pruning {
+			return nil
+		}
+		if m.pruneResults != nil {
+			switch msg.String() {
+			case "esc", "backspace", "enter":
+				m.prune = nil
+				m.pruneResults = nil
+				m.state = "list"
+			}
+			return nil
+		}
+
+		switch msg.String() {
+		case "up", "k":
+			if m.pruneCursor > 0 {
+				m.pruneCursor--
+			}
+		case "down", "j":
+			if m.pruneCursor < len(m.prune)-1 {
+				m.pruneCursor++
+			}
+		case " ":
+			m.prune[m.pruneCursor].selected = !m.prune[m.pruneCursor].selected
+		case "a":
+			// Check all, or uncheck all when all are checked
+			selected, _ := m.pruneSelection()
+			all := len(selected) < len(m.prune)
+			for i := range m.prune {
+				m.prune[i].selected = all
+			}
+		case "y", "Y":
+			if selected, _ := m.pruneSelection(); len(selected) > 0 {
+				m.pruning = true
+				return tea.Batch(m.pruneImages(selected), m.spin.Tick)
+			}
+		case "esc", "backspace":
+			m.prune = nil
+			m.state = "list"
+		}
+	}
+	return nil
+}
+
+// renderPrune renders the prune preview, or the results once it ran
+func (m *ImageModel) renderPrune() string {
+	if m.pruneResults != nil {
+		return m.renderPruneResults()
+	}
+
+	selected, size := m.pruneSelection()
+	sections := []string{
+		StyleTitle.Render(fmt.Sprintf("Prune Images (%s)", m.imageFilter.Summary())),
+		fmt.Sprintf("%d of %d images checked • %s reclaimed", len(selected), len(m.prune), formatter.FormatSize(float64(size))),
+		StyleSubtle.Render("Layers shared with images that are kept stay on disk and are not counted."),
+		"",
+	}
+
+	var lines []string
+	for i, c := range m.prune {
+		check := "[ ]"
+		if c.selected {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %-40s %-11s %s", check, truncate(c.title, 40),
+			formatter.FormatSize(float64(uniqueSize(c.image))),
+			formatter.FormatTime(time.Unix(c.image.Created, 0)))
+		if i == m.pruneCursor {
+			line = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	sections = append(sections, StyleInfoBox.Render(strings.Join(lines, "\n")))
+
+	if len(m.pruneSkipped) > 0 {
+		sections = append(sections, "", StyleWarning.Render("Kept, as containers use them:"))
+		for _, s := range m.pruneSkipped {
+			sections = append(sections, StyleSubtle.Render("  "+s))
+		}
+	}
+
+	if m.pruning {
+		sections = append(sections, "", fmt.Sprintf("%s Removing %d images...", m.spin.View(), len(selected)))
+	} else {
+		sections = append(sections, "", StyleHelp.Render("↑/↓: Select • space: Check • a: Check all/none • y: Remove checked images • esc: Cancel"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// renderPruneResults renders what a prune removed and what failed
+func (m *ImageModel) renderPruneResults() string {
+	removed := 0
+	var freed int64
+	var failures []string
+	for _, r := range m.pruneResults {
+		if r.Error != nil {
+			failures = append(failures, StyleError.Render(fmt.Sprintf("  %s: %v", r.Title, r.Error)))
+			continue
+		}
+		removed++
+		freed += r.Size
+	}
+
+	sections := []string{
+		StyleTitle.Render("Prune Images"),
+		StyleSuccess.Render(fmt.Sprintf("Removed %d of %d images, reclaiming %s", removed, len(m.pruneResults), formatter.FormatSize(float64(freed)))),
+	}
+	if len(failures) > 0 {
+		sections = append(sections, "", StyleError.Render(fmt.Sprintf("%d images could not be removed:", len(failures))))
+		sections = append(sections, failures...)
+	}
+	sections = append(sections, "", StyleHelp.Render("Enter/esc: Back"))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/registryauth"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// maxParallelPulls is how many queued pulls run at the same time
+const maxParallelPulls = 3
+
+// States of a queued pull
+const (
+	pullQueued    = "queued"
+	pullRunning   = "pulling"
+	pullDone      = "done"
+	pullFailed    = "failed"
+	pullCancelled = "cancelled"
+)
+
+// ImagePullProgressMsg carries a progress message of a queued pull
+type ImagePullProgressMsg struct {
+	PullID  int
+	Message jsonmessage.JSONMessage
+}
+
+// pullJob is an image in the pull queue
+type pullJob struct {
+	id       int
+	ref      string
+	state    string
+	progress *pullProgress
+	updates  <-chan tea.Msg
+	cancel   context.CancelFunc
+	err      error
+}
+
+// finished reports whether the pull has ended
+func (j *pullJob) finished() bool {
+	return j.state == pullDone || j.state == pullFailed || j.state == pullCancelled
+}
+
+// parsePullRefs splits the pull input into image references
+func parsePullRefs(value string) ([]string, error) {
+	refs := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, ref := range refs {
+		if err := validateImage(ref); err != nil {
+			return nil, fmt.Errorf("%s: %w", ref, err)
+		}
+	}
+	return refs, nil
+}
+
+// queuePulls adds images to the pull queue and starts as many as allowed
+func (m *ImageModel) queuePulls(refs []string) tea.Cmd {
+	for _, ref := range refs {
+		m.nextPullID++
+		m.pulls = append(m.pulls, &pullJob{
+			id:       m.nextPullID,
+			ref:      ref,
+			state:    pullQueued,
+			progress: newPullProgress(),
+		})
+	}
+	return m.startPulls()
+}
+
+// startPulls starts queued pulls while fewer than maxParallelPulls run
+func (m *ImageModel) startPulls() tea.Cmd {
+	running := 0
+	for _, job := range m.pulls {
+		if job.state == pullRunning {
+			running++
+		}
+	}
+
+	var cmds []tea.Cmd
+	for _, job := range m.pulls {
+		if running >= maxParallelPulls {
+			break
+		}
+		if job.state != pullQueued {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		job.cancel = cancel
+		job.state = pullRunning
+		job.updates = m.runPull(ctx, job.id, job.ref)
+		cmds = append(cmds, waitForUpdate(job.updates))
+		running++
+	}
+	return tea.Batch(cmds...)
+}
+
+// runPull pulls an image, sending its progress and a final ImagePullMsg on
+// the returned channel, which is closed when the pull ends or ctx is done
+func (m *ImageModel) runPull(ctx context.Context, id int, ref string) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+
+		send := func(msg tea.Msg) bool {
+			select {
+			case updates <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		err := func() error {
+			options, err := registryauth.PullOptions(ref)
+			if err != nil {
+				return err
+			}
+			reader, err := m.docker.Client.ImagePull(ctx, ref, options)
+			if err != nil {
+				return err
+			}
+			defer reader.Close()
+
+			return decodePullStream(reader, func(msg jsonmessage.JSONMessage) {
+				send(ImagePullProgressMsg{PullID: id, Message: msg})
+			})
+		}()
+		send(ImagePullMsg{PullID: id, Image: ref, Success: err == nil, Error: err})
+	}()
+
+	return updates
+}
+
+// pullJob returns the queued pull with the given ID
+func (m *ImageModel) pullJob(id int) *pullJob {
+	for _, job := range m.pulls {
+		if job.id == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// cancelPull stops a running pull or drops a queued one
+func (m *ImageModel) cancelPull(job *pullJob) tea.Cmd {
+	if job.finished() {
+		return nil
+	}
+	if job.cancel != nil {
+		job.cancel()
+	}
+	job.state = pullCancelled
+	return m.startPulls()
+}
+
+// clearFinishedPulls removes ended pulls from the queue
+func (m *ImageModel) clearFinishedPulls() {
+	pulls := m.pulls[:0]
+	for _, job := range m.pulls {
+		if !job.finished() {
+			pulls = append(pulls, job)
+		}
+	}
+	m.pulls = pulls
+	m.pullCursor = min(m.pullCursor, max(len(m.pulls)-1, 0))
+}
+
+// activePulls counts the pulls that are queued or running
+func (m *ImageModel) activePulls() int {
+	active := 0
+	for _, job := range m.pulls {
+		if !job.finished() {
+			active++
+		}
+	}
+	return active
+}
+
+// updatePull handles the messages of queued pulls
+func (m *ImageModel) updatePull(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case ImagePullProgressMsg:
+		job := m.pullJob(msg.PullID)
+		// Messages still in flight after a cancel are dropped
+		if job == nil || job.state != pullRunning {
+			return nil
+		}
+		job.progress.update(msg.Message)
+		return waitForUpdate(job.updates)
+
+	case ImagePullMsg:
+		job := m.pullJob(msg.PullID)
+		if job == nil || job.state != pullRunning {
+			return nil
+		}
+		job.cancel()
+		switch {
+		case msg.Error == nil:
+			job.state = pullDone
+		case errors.Is(msg.Error, context.Canceled):
+			job.state = pullCancelled
+		default:
+			job.state = pullFailed
+			job.err = msg.Error
+		}
+
+		cmds := []tea.Cmd{m.startPulls()}
+		if job.state == pullDone {
+			cmds = append(cmds, m.fetchImages())
+		}
+		return tea.Batch(cmds...)
+	}
+	return nil
+}
+
+// updatePullQueue handles keys in the pull queue view
+func (m *ImageModel) updatePullQueue(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		if m.pullCursor > 0 {
+			m.pullCursor--
+		}
+	case "down", "j":
+		if m.pullCursor < len(m.pulls)-1 {
+			m.pullCursor++
+		}
+	case "x":
+		if m.pullCursor < len(m.pulls) {
+			return m.cancelPull(m.pulls[m.pullCursor])
+		}
+	case "c":
+		m.clearFinishedPulls()
+	case "p":
+		m.state = "pull"
+		m.pullErr = ""
+		m.textInput.Reset()
+		m.textInput.Focus()
+	case "esc", "backspace":
+		m.state = "list"
+	}
+	return nil
+}
+
+// pullStateStyle returns the style of a pull state label
+func pullStateStyle(state string) lipgloss.Style {
+	switch state {
+	case pullDone:
+		return StyleSuccess
+	case pullFailed:
+		return StyleError
+	case pullCancelled, pullQueued:
+		return StyleSubtle
+	}
+	return StyleWarning
+}
+
+// renderPullQueue renders every queued pull with its overall progress and
+// the layers of the selected one
+func (m *ImageModel) renderPullQueue() string {
+	running, queued := 0, 0
+	for _, job := range m.pulls {
+		switch job.state {
+		case pullRunning:
+			running++
+		case pullQueued:
+			queued++
+		}
+	}
+
+	sections := []string{StyleTitle.Render(fmt.Sprintf("Pulls (%d running, %d queued)", running, queued))}
+	if len(m.pulls) == 0 {
+		sections = append(sections, StyleInfoBox.Render("No pulls. Press p to pull an image."))
+	}
+
+	var lines []string
+	for i, job := range m.pulls {
+		cursor := "  "
+		if i == m.pullCursor {
+			cursor = "> "
+		}
+		line := fmt.Sprintf("%s%-30s %s", cursor, job.ref, pullStateStyle(job.state).Render(fmt.Sprintf("%-9s", job.state)))
+		switch {
+		case job.state == pullRunning:
+			if summary := job.progress.Summary(); summary != "" {
+				line += fmt.Sprintf(" %s %s", job.progress.bar.ViewAs(job.progress.percent()), summary)
+			} else if job.progress.status != "" {
+				line += " " + StyleSubtle.Render(job.progress.status)
+			}
+		case job.err != nil:
+			line += " " + StyleError.Render(job.err.Error())
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if m.pullCursor < len(m.pulls) {
+		if job := m.pulls[m.pullCursor]; job.state == pullRunning && len(job.progress.layers) > 0 {
+			sections = append(sections, "", StyleSubtle.Render("Layers of "+job.ref), job.progress.View())
+		}
+	}
+
+	sections = append(sections, "", StyleHelp.Render("↑/↓: Select • x: Cancel • p: Pull more • c: Clear finished • esc: Back"))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/registryauth"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// ImagePushProgressMsg carries a progress message of the running push
+type ImagePushProgressMsg struct {
+	Message jsonmessage.JSONMessage
+}
+
+// ImagePushMsg reports the end of a push with the digest of the pushed
+// manifest
+type ImagePushMsg struct {
+	Ref    string
+	Digest string
+	Error  error
+}
+
+// imagePush is the state of a push
+type imagePush struct {
+	ref      string
+	progress *pullProgress
+	updates  <-chan tea.Msg
+	cancel   context.CancelFunc
+	done     bool
+	digest   string
+	err      error
+}
+
+// startPush starts pushing a tag of the selected image
+func (m *ImageModel) startPush(ref string) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.push = &imagePush{
+		ref:      ref,
+		progress: newPullProgress(),
+		cancel:   cancel,
+	}
+	m.push.updates = m.runPush(ctx, ref)
+	m.state = "push"
+	return tea.Batch(waitForUpdate(m.push.updates), m.spin.Tick)
+}
+
+// runPush pushes an image, sending its progress and a final ImagePushMsg on
+// the returned channel, which is closed when the push ends or ctx is done
+func (m *ImageModel) runPush(ctx context.Context, ref string) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+
+		send := func(msg tea.Msg) bool {
+			select {
+			case updates <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var result types.PushResult
+		err := func() error {
+			options, err := registryauth.PushOptions(ref)
+			if err != nil {
+				return err
+			}
+			reader, err := m.docker.Client.ImagePush(ctx, ref, options)
+			if err != nil {
+				return err
+			}
+			defer reader.Close()
+
+			return decodePullStream(reader, func(msg jsonmessage.JSONMessage) {
+				// The digest comes last as auxiliary data rather than a status
+				if msg.Aux != nil {
+					json.Unmarshal(*msg.Aux, &result)
+					return
+				}
+				send(ImagePushProgressMsg{Message: msg})
+			})
+		}()
+		send(ImagePushMsg{Ref: ref, Digest: result.Digest, Error: err})
+	}()
+
+	return updates
+}
+
+// pushDenied reports whether a push failed for lack of credentials
+func pushDenied(err error) bool {
+	s := strings.ToLower(err.Error())
+	return strings.Contains(s, "unauthorized") || strings.Contains(s, "denied") ||
+		strings.Contains(s, "authentication required")
+}
+
+// updatePush handles messages while the push view is shown
+func (m *ImageModel) updatePush(msg tea.Msg) tea.Cmd {
+	p := m.push
+	switch msg := msg.(type) {
+	case ImagePushProgressMsg:
+		if p.done {
+			return nil
+		}
+		p.progress.update(msg.Message)
+		return waitForUpdate(p.updates)
+
+	case ImagePushMsg:
+		if p.done {
+			return nil
+		}
+		p.cancel()
+		p.done = true
+		p.digest = msg.Digest
+		if msg.Error != nil && !errors.Is(msg.Error, context.Canceled) {
+			p.err = msg.Error
+		}
+		if p.err == nil {
+			// The image gains a repo digest
+			return m.fetchImages()
+		}
+		return nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "backspace":
+			p.cancel()
+			m.push = nil
+			m.state = "list"
+		case "L":
+			if p.err != nil && pushDenied(p.err) {
+				server, err := registryauth.ServerForImage(p.ref)
+				if err == nil && server != registryauth.DockerHubServer {
+					m.loginServer = server
+				} else {
+					m.loginServer = ""
+				}
+				m.push = nil
+				return m.startLogin()
+			}
+		}
+	}
+	return nil
+}
+
+// renderPush renders the layers of the push and its result
+func (m *ImageModel) renderPush() string {
+	p := m.push
+	sections := []string{StyleTitle.Render(fmt.Sprintf("Push: %s", p.ref))}
+
+	if len(p.progress.layers) > 0 || p.progress.status != "" {
+		sections = append(sections, p.progress.View(), "")
+	}
+
+	help := "esc: Back"
+	switch {
+	case !p.done:
+		sections = append(sections, fmt.Sprintf("%s Pushing...", m.spin.View()))
+		help = "esc: Cancel"
+	case p.err != nil:
+		sections = append(sections, StyleError.Render(fmt.Sprintf("Push failed: %v", p.err)))
+		if pushDenied(p.err) {
+			sections = append(sections, StyleSubtle.Render("The registry refused the credentials. Log in and push again."))
+			help = "L: Log in • esc: Back"
+		}
+	default:
+		sections = append(sections, StyleSuccess.Render(fmt.Sprintf("Pushed %s", p.ref)))
+		if p.digest != "" {
+			sections = append(sections, fmt.Sprintf("Digest: %s", p.digest))
+		}
+	}
+
+	sections = append(sections, "", StyleHelp.Render(help))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
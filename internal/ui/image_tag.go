@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/distribution/reference"
+)
+
+// validateTag checks a repository:tag to give an image
+func validateTag(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("a repository:tag is required")
+	}
+	named, err := reference.ParseNormalizedNamed(value)
+	if err != nil {
+		return err
+	}
+	if _, ok := named.(reference.Canonical); ok {
+		return fmt.Errorf("a tag cannot contain a digest")
+	}
+	return nil
+}
+
+// imageTags returns the tags of an image, leaving out the <none>:<none>
+// placeholder of untagged images
+func imageTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		if tag != "<none>:<none>" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// startTag prompts for a new tag for the selected image
+func (m *ImageModel) startTag(item ImageItem) tea.Cmd {
+	m.selectedImage = &item.image
+	m.tagErr = ""
+	m.tagInput.Reset()
+	// Start from the repository so only the tag needs typing
+	if tags := imageTags(item.image.RepoTags); len(tags) > 0 {
+		if i := strings.LastIndex(tags[0], ":"); i > strings.LastIndex(tags[0], "/") {
+			m.tagInput.SetValue(tags[0][:i+1])
+		}
+	}
+	m.state = "tag"
+	return m.tagInput.Focus()
+}
+
+// tagImage returns a command that adds a tag to an image
+func (m *ImageModel) tagImage(imageID, target string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.docker.Client.ImageTag(context.Background(), imageID, target)
+		return ImageActionMsg{
+			Action:  "tag",
+			ImageID: imageID,
+			Target:  target,
+			Error:   err,
+		}
+	}
+}
+
+// updateTag handles keys in the tag prompt
+func (m *ImageModel) updateTag(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		target := strings.TrimSpace(m.tagInput.Value())
+		if err := validateTag(target); err != nil {
+			m.tagErr = err.Error()
+			return nil
+		}
+		m.state = "list"
+		return m.tagImage(m.selectedImage.ID, target)
+
+	case "esc":
+		m.state = "list"
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.tagInput, cmd = m.tagInput.Update(msg)
+	m.tagErr = ""
+	return cmd
+}
+
+// chooseTag lists the tags of the selected image to pick the one an
+// action applies to, "untag" or "push". An image with a single tag skips
+// the list for a push.
+func (m *ImageModel) chooseTag(item ImageItem, action string) tea.Cmd {
+	tags := imageTags(item.image.RepoTags)
+	if len(tags) == 0 {
+		m.notice = fmt.Sprintf("Image %s has no tags", shortImageID(item.image.ID))
+		return nil
+	}
+	m.selectedImage = &item.image
+	m.tagAction = action
+	m.tagCursor = 0
+	if action == "push" && len(tags) == 1 {
+		return m.startPush(tags[0])
+	}
+	m.state = "tags"
+	return nil
+}
+
+// updateChooseTag handles keys in the tag list
+func (m *ImageModel) updateChooseTag(msg tea.KeyMsg) tea.Cmd {
+	tags := imageTags(m.selectedImage.RepoTags)
+	switch msg.String() {
+	case "up", "k":
+		if m.tagCursor > 0 {
+			m.tagCursor--
+		}
+	case "down", "j":
+		if m.tagCursor < len(tags)-1 {
+			m.tagCursor++
+		}
+	case "enter":
+		tag := tags[m.tagCursor]
+		if m.tagAction == "push" {
+			return m.startPush(tag)
+		}
+		m.confirmMsg = fmt.Sprintf("Remove the tag %s?", tag)
+		if len(tags) == 1 {
+			m.confirmMsg = fmt.Sprintf("%s is the only tag of this image. Removing it also removes the image.", tag)
+			if warning := usageWarning(m.usage[m.selectedImage.ID]); warning != "" {
+				m.confirmMsg = fmt.Sprintf("%s is the only tag of this image.\n\n%s", tag, StyleWarning.Render(warning+
+					" The daemon refuses to remove the last tag of an image a container uses."))
+			}
+		}
+		m.confirmAction = "untag"
+		m.confirmTarget = tag
+		m.state = "confirm"
+	case "esc":
+		m.state = "list"
+	}
+	return nil
+}
+
+// renderTag renders the tag prompt
+func (m *ImageModel) renderTag() string {
+	lines := []string{
+		fmt.Sprintf("New tag for %s:", shortImageID(m.selectedImage.ID)),
+		m.tagInput.View(),
+	}
+	if tags := imageTags(m.selectedImage.RepoTags); len(tags) > 0 {
+		lines = append(lines, "", StyleSubtle.Render("Current tags: "+strings.Join(tags, ", ")))
+	}
+	if m.tagErr != "" {
+		lines = append(lines, "", StyleError.Render(m.tagErr))
+	}
+	lines = append(lines, "", "Press Enter to tag or Esc to cancel")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleTitle.Render("Tag Image"),
+		"",
+		StyleInfoBox.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// renderChooseTag renders the tags of the selected image
+func (m *ImageModel) renderChooseTag() string {
+	title, help := "Remove Tag", "Enter: Remove tag"
+	if m.tagAction == "push" {
+		title, help = "Push", "Enter: Push tag"
+	}
+
+	var lines []string
+	for i, tag := range imageTags(m.selectedImage.RepoTags) {
+		if i == m.tagCursor {
+			lines = append(lines, lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("> "+tag))
+		} else {
+			lines = append(lines, "  "+tag)
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleTitle.Render(fmt.Sprintf("%s: %s", title, shortImageID(m.selectedImage.ID))),
+		"",
+		StyleInfoBox.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		"",
+		StyleHelp.Render(fmt.Sprintf("↑/↓: Select • %s • Esc: Cancel", help)),
+	)
+}
@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/container"
+)
+
+// ShowContainerMsg asks the main model to open the container view with a
+// container selected
+type ShowContainerMsg struct {
+	ContainerID string
+}
+
+// imageContainers groups containers, running or not, by the ID of their image
+func imageContainers(containers []container.Summary) map[string][]Summary {
+	usage := map[string][]Summary{}
+	for _, c := range containers {
+		usage[c.ImageID] = append(usage[c.ImageID], containerSummaryToSummary(c))
+	}
+	for _, list := range usage {
+		// Running containers first, then by name
+		sort.Slice(list, func(a, b int) bool {
+			if ra, rb := list[a].State == "running", list[b].State == "running"; ra != rb {
+				return ra
+			}
+			return containerName(list[a]) < containerName(list[b])
+		})
+	}
+	return usage
+}
+
+// usageSummary describes how many containers use an image
+func usageSummary(containers []Summary) string {
+	if len(containers) == 0 {
+		return "unused"
+	}
+	running := 0
+	for _, c := range containers {
+		if c.State == "running" {
+			running++
+		}
+	}
+	noun := "containers"
+	if len(containers) == 1 {
+		noun = "container"
+	}
+	return fmt.Sprintf("%d %s (%d running)", len(containers), noun, running)
+}
+
+// usageWarning lists the containers that use an image, or returns "" when
+// none do
+func usageWarning(containers []Summary) string {
+	if len(containers) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, fmt.Sprintf("%s (%s)", containerName(c), c.State))
+	}
+	return fmt.Sprintf("Used by %s: %s.", usageSummary(containers), strings.Join(names, ", "))
+}
+
+// showImageContainers lists the containers that use the selected image
+func (m *ImageModel) showImageContainers(item ImageItem) {
+	if len(m.usage[item.image.ID]) == 0 {
+		m.notice = fmt.Sprintf("No container uses %s", item.title)
+		return
+	}
+	m.selectedImage = &item.image
+	m.usageTitle = item.title
+	m.usageCursor = 0
+	m.state = "containers"
+}
+
+// updateImageContainers handles keys in the container list of an image
+func (m *ImageModel) updateImageContainers(msg tea.KeyMsg) tea.Cmd {
+	containers := m.usage[m.selectedImage.ID]
+	switch msg.String() {
+	case "up", "k":
+		if m.usageCursor > 0 {
+			m.usageCursor--
+		}
+	case "down", "j":
+		if m.usageCursor < len(containers)-1 {
+			m.usageCursor++
+		}
+	case "enter":
+		if m.usageCursor < len(containers) {
+			id := containers[m.usageCursor].ID
+			m.state = "list"
+			return func() tea.Msg {
+				return ShowContainerMsg{ContainerID: id}
+			}
+		}
+	case "esc", "backspace":
+		m.state = "list"
+	}
+	return nil
+}
+
+// renderImageContainers renders the containers that use the selected image
+func (m *ImageModel) renderImageContainers() string {
+	containers := m.usage[m.selectedImage.ID]
+	lines := []string{StyleSubtle.Render(fmt.Sprintf("  %-30s %-12s %s", "NAME", "STATE", "STATUS"))}
+	for i, c := range containers {
+		state := StyleSubtle
+		if c.State == "running" {
+			state = StyleSuccess
+		}
+		line := fmt.Sprintf("%-30s %s %s", truncate(containerName(c), 30), state.Render(fmt.Sprintf("%-12s", c.State)), c.Status)
+		if i == m.usageCursor {
+			line = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		StyleTitle.Render(fmt.Sprintf("Containers of %s", m.usageTitle)),
+		StyleSubtle.Render(usageSummary(containers)),
+		"",
+		StyleInfoBox.Render(strings.Join(lines, "\n")),
+		"",
+		StyleHelp.Render("↑/↓: Select • Enter: Go to container • esc: Back"),
+	)
+}
@@ -14,6 +14,12 @@ import (
 
 type ReturnToMainMsg struct{}
 
+// inputCapturer is implemented by views that can have a text input focused.
+// While capturing, global navigation keys are passed to the view instead.
+type inputCapturer interface {
+	CapturingInput() bool
+}
+
 // DockerInfoMsg contains Docker daemon information
 type DockerInfoMsg struct {
 	ContainerCount int
@@ -33,6 +39,7 @@ const (
 	ViewNetworks
 	ViewVolumes
 	ViewSystem
+	ViewProjects
 )
 
 // MainModel is the root model for the application
@@ -44,6 +51,7 @@ type MainModel struct {
 	networks       *NetworkModel
 	volumes        *VolumeModel
 	system         *SystemModel
+	projects       *ProjectModel
 	width          int
 	height         int
 	containerCount int
@@ -54,8 +62,9 @@ type MainModel struct {
 	error          error
 }
 
-// NewMainModel creates and initializes the main model
-func NewMainModel(width, height int) tea.Model {
+// NewMainModel creates and initializes the main model. composeFiles names
+// the compose files of a project to manage in the projects view.
+func NewMainModel(width, height int, composeFiles []string) tea.Model {
 	// Create Docker client, etc.
 	dockerClient, err := client.NewDockerClient(context.Background())
 	if err != nil {
@@ -88,6 +97,10 @@ func NewMainModel(width, height int) tea.Model {
 	system.width = width
 	system.height = height
 
+	projects := NewProjectModel(dockerClient, composeFiles)
+	projects.width = width
+	projects.height = height
+
 	m := &MainModel{
 		dockerClient: dockerClient,
 		currentView:  ViewMain,
@@ -99,6 +112,7 @@ func NewMainModel(width, height int) tea.Model {
 		networks:     networks,
 		volumes:      volumes,
 		system:       system,
+		projects:     projects,
 	}
 
 	return m
@@ -160,6 +174,10 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if msg.String() != "ctrl+c" && m.capturingInput() {
+			break
+		}
+
 		if msg.String() == "m" {
 			m.currentView = ViewMain
 			return m, m.fetchDockerInfo()
@@ -228,6 +246,18 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 			cmds = append(cmds, m.system.Init())
 			return m, tea.Batch(cmds...)
+
+		case "6":
+			m.currentView = ViewProjects
+			// Send a window size message to ensure proper initialization
+			cmds = append(cmds, func() tea.Msg {
+				return tea.WindowSizeMsg{
+					Width:  m.width,
+					Height: m.height,
+				}
+			})
+			cmds = append(cmds, m.projects.Init())
+			return m, tea.Batch(cmds...)
 		}
 
 		// This catches "0" from anywhere and handles as "return to main menu"
@@ -261,6 +291,10 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.system.width = msg.Width
 			m.system.height = msg.Height
 		}
+		if m.projects != nil {
+			m.projects.width = msg.Width
+			m.projects.height = msg.Height
+		}
 
 	case DockerInfoMsg:
 		m.loading = false
@@ -272,6 +306,43 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.imageCount = msg.ImageCount
 		m.serverVersion = msg.ServerVersion
 		m.engineVersion = msg.EngineVersion
+
+	case ShowContainerMsg:
+		m.currentView = ViewContainers
+		m.containers.focusContainer(msg.ContainerID)
+		cmds = append(cmds, func() tea.Msg {
+			return tea.WindowSizeMsg{
+				Width:  m.width,
+				Height: m.height,
+			}
+		})
+		cmds = append(cmds, m.containers.Init())
+		return m, tea.Batch(cmds...)
+
+	case ContainerUpdateProgressMsg, ContainerUpdateDoneMsg:
+		// The update workflow must run to its end even if the view changed
+		if m.currentView != ViewContainers {
+			_, cmd = m.containers.Update(msg)
+			return m, cmd
+		}
+
+	case ProjectProgressMsg, ProjectDoneMsg:
+		// Compose operations must run to their end even if the view changed
+		if m.currentView != ViewProjects {
+			_, cmd = m.projects.Update(msg)
+			return m, cmd
+		}
+
+	case ImagePullProgressMsg, ImagePullMsg, ImagePruneMsg,
+		ImageExploreProgressMsg, ImageExploreMsg,
+		ImagePushProgressMsg, ImagePushMsg,
+		ImageBuildProgressMsg, ImageBuildMsg,
+		ImageArchiveProgressMsg, ImageLoadProgressMsg, ImageArchiveMsg:
+		// Image operations keep running while another view is shown
+		if m.currentView != ViewImages {
+			_, cmd = m.images.Update(msg)
+			return m, cmd
+		}
 	}
 
 	// Update the current view
@@ -315,11 +386,39 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.system = newSystemModel
 		}
 		cmds = append(cmds, cmd)
+
+	case ViewProjects:
+		var newModel tea.Model
+		newModel, cmd = m.projects.Update(msg)
+		if newProjectModel, ok := newModel.(*ProjectModel); ok {
+			m.projects = newProjectModel
+		}
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// capturingInput reports whether the current view is taking text input
+func (m *MainModel) capturingInput() bool {
+	var view tea.Model
+	switch m.currentView {
+	case ViewContainers:
+		view = m.containers
+	case ViewImages:
+		view = m.images
+	case ViewNetworks:
+		view = m.networks
+	case ViewProjects:
+		view = m.projects
+	}
+
+	if capturer, ok := view.(inputCapturer); ok {
+		return capturer.CapturingInput()
+	}
+	return false
+}
+
 // View renders the current view
 func (m *MainModel) View() string {
 	if m.error != nil {
@@ -355,6 +454,8 @@ func (m *MainModel) View() string {
 		return m.volumes.View()
 	case ViewSystem:
 		return m.system.View()
+	case ViewProjects:
+		return m.projects.View()
 	default:
 		return "Invalid view"
 	}
@@ -381,6 +482,7 @@ func (m *MainModel) renderMainView() string {
 		"3. Network Management",
 		"4. Volume Management",
 		"5. System Management",
+		"6. Compose Projects",
 		"0. Exit",
 	}
 	menu := StyleMenu.Render(strings.Join(menuItems, "\n"))
@@ -301,6 +301,17 @@ func (m *NetworkModel) updateInputs(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// CapturingInput reports whether a text input currently has focus
+func (m *NetworkModel) CapturingInput() bool {
+	switch m.state {
+	case "create":
+		return true
+	case "list":
+		return m.networkList.SettingFilter()
+	}
+	return false
+}
+
 // Update handles messages and updates the model
 func (m *NetworkModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
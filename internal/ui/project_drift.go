@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/client"
+	"github.com/Gostatsog/dockerNav/internal/compose"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ProjectDriftMsg carries the differences between a project's compose files
+// and its containers
+type ProjectDriftMsg struct {
+	Drifts []compose.ServiceDrift
+	Error  error
+}
+
+// fetchDrift returns a command that compares a project with its containers
+func (m *ProjectModel) fetchDrift(project *compose.Project) tea.Cmd {
+	return func() tea.Msg {
+		drifts, err := compose.Drift(context.Background(), m.docker.Client, project)
+		return ProjectDriftMsg{Drifts: drifts, Error: err}
+	}
+}
+
+// recreateOutOfDate recreates every declared service whose containers no
+// longer match the compose files. Drift is computed again so the services
+// recreated are the ones out of date right now.
+func recreateOutOfDate(ctx context.Context, docker *client.DockerClient, project *compose.Project, progress compose.Progress) error {
+	drifts, err := compose.Drift(ctx, docker.Client, project)
+	if err != nil {
+		return err
+	}
+
+	services := outOfDateServices(drifts)
+	if len(services) == 0 {
+		progress("All services are up to date")
+		return nil
+	}
+	for _, service := range services {
+		progress(fmt.Sprintf("Recreating %s", service))
+		if err := compose.Recreate(ctx, docker.Client, project, service, progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outOfDateServices returns the declared services that need recreating
+func outOfDateServices(drifts []compose.ServiceDrift) []string {
+	var services []string
+	for _, d := range drifts {
+		if d.OutOfDate() && !d.Undeclared {
+			services = append(services, d.Service)
+		}
+	}
+	return services
+}
+
+// renderDrift formats the per-service differences for the drift view
+func renderDrift(drifts []compose.ServiceDrift) string {
+	var b strings.Builder
+	for _, d := range drifts {
+		switch {
+		case d.Undeclared:
+			b.WriteString(StyleWarning.Render(fmt.Sprintf("%s: not in compose files", d.Service)) + "\n")
+		case d.OutOfDate():
+			b.WriteString(StyleError.Render(fmt.Sprintf("%s: out of date", d.Service)) + "\n")
+		default:
+			b.WriteString(StyleSuccess.Render(fmt.Sprintf("%s: up to date", d.Service)) + "\n")
+		}
+
+		for _, number := range d.Missing {
+			b.WriteString(fmt.Sprintf("  missing replica %d\n", number))
+		}
+		for _, name := range d.Extra {
+			b.WriteString(fmt.Sprintf("  extra container %s\n", name))
+		}
+
+		container := ""
+		for _, diff := range d.Differences {
+			if diff.Container != container {
+				container = diff.Container
+				b.WriteString(fmt.Sprintf("  %s\n", StyleSubtle.Render(container)))
+			}
+			b.WriteString(fmt.Sprintf("    %s\n", diff.Field))
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorSuccess).Render(fmt.Sprintf("      - declared: %s", diff.Declared)) + "\n")
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorError).Render(fmt.Sprintf("      + running:  %s", diff.Actual)) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		return "The project declares no services"
+	}
+	return b.String()
+}
@@ -0,0 +1,882 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gostatsog/dockerNav/internal/client"
+	"github.com/Gostatsog/dockerNav/internal/compose"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// projectLogTail is the number of log lines fetched per project container
+const projectLogTail = 200
+
+// ProjectListMsg carries the discovered compose projects
+type ProjectListMsg struct {
+	Projects []*compose.ProjectState
+	Error    error
+}
+
+// ProjectProgressMsg carries a single step of a project operation
+type ProjectProgressMsg struct {
+	Step string
+}
+
+// ProjectDoneMsg signals the end of a project operation
+type ProjectDoneMsg struct {
+	Action  string
+	Project string
+	Error   error
+}
+
+// ProjectLogsMsg carries the combined logs of a project
+type ProjectLogsMsg struct {
+	Logs  string
+	Error error
+}
+
+// ProjectItem represents a compose project in the list
+type ProjectItem struct {
+	project *compose.ProjectState
+}
+
+// FilterValue implements list.Item interface
+func (i ProjectItem) FilterValue() string { return i.project.Name }
+
+// Title returns the title for the list item
+func (i ProjectItem) Title() string {
+	running, total := i.project.Running()
+	title := fmt.Sprintf("%s  %s", i.project.Name, StyleSuccess.Render(fmt.Sprintf("%d/%d running", running, total)))
+	if missing := i.project.Missing(); len(missing) > 0 {
+		title += " " + StyleError.Render("missing: "+strings.Join(missing, ", "))
+	}
+	return title
+}
+
+// Description returns the description for the list item
+func (i ProjectItem) Description() string {
+	if i.project.LoadError != nil {
+		return StyleWarning.Render(fmt.Sprintf("Compose file unreadable: %v", i.project.LoadError))
+	}
+	if len(i.project.ConfigFiles) == 0 {
+		return "No compose files recorded"
+	}
+	return strings.Join(i.project.ConfigFiles, ", ")
+}
+
+// ServiceItem represents a service of a compose project
+type ServiceItem struct {
+	service *compose.ServiceState
+}
+
+// FilterValue implements list.Item interface
+func (i ServiceItem) FilterValue() string { return i.service.Name }
+
+// Title returns the title for the list item
+func (i ServiceItem) Title() string {
+	svc := i.service
+	switch {
+	case svc.Desired < 0:
+		return fmt.Sprintf("%s  %s", svc.Name, StyleWarning.Render(fmt.Sprintf("%d running • not in compose file", svc.Running)))
+	case svc.Missing():
+		return fmt.Sprintf("%s  %s", svc.Name, StyleError.Render(fmt.Sprintf("%d/%d running", svc.Running, svc.Desired)))
+	default:
+		return fmt.Sprintf("%s  %s", svc.Name, StyleSuccess.Render(fmt.Sprintf("%d/%d running", svc.Running, svc.Desired)))
+	}
+}
+
+// Description returns the description for the list item
+func (i ServiceItem) Description() string {
+	if len(i.service.Containers) == 0 {
+		return "No containers"
+	}
+	parts := make([]string, 0, len(i.service.Containers))
+	for _, c := range i.service.Containers {
+		parts = append(parts, fmt.Sprintf("%s (%s)", compose.DisplayName(c), c.State))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// ProjectKeyMap defines keybindings for compose project operations
+type ProjectKeyMap struct {
+	Refresh  key.Binding
+	Details  key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Restart  key.Binding
+	Stop     key.Binding
+	Pull     key.Binding
+	Logs     key.Binding
+	Scale    key.Binding
+	Drift    key.Binding
+	Recreate key.Binding
+	Back     key.Binding
+	MainMenu key.Binding
+}
+
+// DefaultProjectKeyMap returns default compose project keybindings
+func DefaultProjectKeyMap() ProjectKeyMap {
+	return ProjectKeyMap{
+		Refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refresh"),
+		),
+		Details: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "services"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "down"),
+		),
+		Restart: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "restart"),
+		),
+		Stop: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "stop"),
+		),
+		Pull: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pull"),
+		),
+		Logs: key.NewBinding(
+			key.WithKeys("l"),
+			key.WithHelp("l", "logs"),
+		),
+		Scale: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "scale service"),
+		),
+		Drift: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "drift"),
+		),
+		Recreate: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "recreate out-of-date"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc", "backspace"),
+			key.WithHelp("esc", "back"),
+		),
+		MainMenu: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "main menu"),
+		),
+	}
+}
+
+// ProjectModel manages the compose project view state
+type ProjectModel struct {
+	docker        *client.DockerClient
+	composeFiles  []string
+	projectList   list.Model
+	serviceList   list.Model
+	keyMap        ProjectKeyMap
+	state         string // "list", "detail", "progress", "logs", "confirm", "scale", "drift"
+	previousState string
+	width         int
+	height        int
+	spin          spinner.Model
+	viewport      viewport.Model
+	projects      []*compose.ProjectState
+	selected      *compose.ProjectState
+	scaleInput    textinput.Model
+	scaleService  string
+	scaleErr      string
+	drifts        []compose.ServiceDrift
+	progress      <-chan tea.Msg
+	steps         []string
+	running       bool
+	action        string
+	confirmMsg    string
+	confirmAction string
+	loading       bool
+	error         error
+}
+
+// newProjectDelegate returns the list delegate used for projects and services
+func newProjectDelegate() list.DefaultDelegate {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.NormalTitle = lipgloss.NewStyle().Foreground(ColorText).Bold(true)
+	delegate.Styles.NormalDesc = lipgloss.NewStyle().Foreground(ColorSubtle)
+	delegate.Styles.SelectedTitle = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	delegate.Styles.SelectedDesc = lipgloss.NewStyle().Foreground(ColorText)
+	delegate.SetHeight(2)
+	return delegate
+}
+
+// NewProjectModel creates a new compose project model. The project defined
+// by composeFiles, if any, is listed even when none of its containers exist.
+func NewProjectModel(docker *client.DockerClient, composeFiles []string) *ProjectModel {
+	keyMap := DefaultProjectKeyMap()
+	additionalKeys := func() []key.Binding {
+		return []key.Binding{
+			keyMap.Refresh,
+			keyMap.Details,
+			keyMap.Up,
+			keyMap.Down,
+			keyMap.Restart,
+			keyMap.Stop,
+			keyMap.Pull,
+			keyMap.Logs,
+			keyMap.Scale,
+			keyMap.Drift,
+			keyMap.Back,
+			keyMap.MainMenu,
+		}
+	}
+
+	projectList := list.New([]list.Item{}, newProjectDelegate(), 0, 0)
+	projectList.Title = "Compose Projects"
+	projectList.Styles.Title = StyleTitle
+	projectList.SetShowStatusBar(true)
+	projectList.SetFilteringEnabled(true)
+	projectList.AdditionalFullHelpKeys = additionalKeys
+	disableListPaging(&projectList)
+
+	serviceList := list.New([]list.Item{}, newProjectDelegate(), 0, 0)
+	serviceList.Styles.Title = StyleTitle
+	serviceList.SetShowStatusBar(false)
+	serviceList.SetFilteringEnabled(false)
+	serviceList.AdditionalFullHelpKeys = additionalKeys
+	disableListPaging(&serviceList)
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(ColorPrimary)
+
+	scaleInput := textinput.New()
+	scaleInput.Placeholder = "Number of replicas"
+	scaleInput.Width = 10
+	scaleInput.CharLimit = 4
+	scaleInput.PromptStyle = lipgloss.NewStyle().Foreground(ColorPrimary)
+	scaleInput.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	vp := viewport.New(0, 0)
+	vp.Style = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary)
+
+	return &ProjectModel{
+		docker:       docker,
+		composeFiles: composeFiles,
+		projectList:  projectList,
+		serviceList:  serviceList,
+		keyMap:       keyMap,
+		state:        "list",
+		spin:         s,
+		viewport:     vp,
+		scaleInput:   scaleInput,
+		loading:      true,
+	}
+}
+
+// disableListPaging unbinds the list paging keys that clash with project
+// actions (u, d and l)
+func disableListPaging(l *list.Model) {
+	l.KeyMap.PrevPage = key.NewBinding(key.WithKeys("left", "pgup"), key.WithHelp("←/pgup", "prev page"))
+	l.KeyMap.NextPage = key.NewBinding(key.WithKeys("right", "pgdown"), key.WithHelp("→/pgdn", "next page"))
+}
+
+// resize updates component sizes for the current dimensions
+func (m *ProjectModel) resize() {
+	headerHeight := 6
+	footerHeight := 2
+	listHeight := m.height - headerHeight - footerHeight
+	if listHeight < 1 {
+		listHeight = 10 // Minimum height
+	}
+
+	listWidth := m.width - 4
+	if listWidth < 10 {
+		listWidth = 40 // Minimum width
+	}
+
+	m.projectList.SetSize(listWidth, listHeight)
+	m.serviceList.SetSize(listWidth, listHeight)
+
+	m.viewport.Width = m.width - 4
+	m.viewport.Height = m.height - headerHeight - footerHeight
+}
+
+// Init initializes the model
+func (m *ProjectModel) Init() tea.Cmd {
+	m.resize()
+	m.loading = true
+	return tea.Batch(m.fetchProjects(), m.spin.Tick)
+}
+
+// fetchProjects returns a command that discovers compose projects
+func (m *ProjectModel) fetchProjects() tea.Cmd {
+	return func() tea.Msg {
+		projects, err := compose.Discover(context.Background(), m.docker.Client, m.composeFiles)
+		return ProjectListMsg{Projects: projects, Error: err}
+	}
+}
+
+// fetchLogs returns a command that fetches the logs of a project
+func (m *ProjectModel) fetchLogs(project string) tea.Cmd {
+	return func() tea.Msg {
+		logs, err := compose.Logs(context.Background(), m.docker.Client, project, projectLogTail)
+		return ProjectLogsMsg{Logs: logs, Error: err}
+	}
+}
+
+// runProjectAction runs a project operation in the background. Progress is
+// sent on the returned channel, which is closed once a ProjectDoneMsg has
+// been delivered.
+func runProjectAction(docker *client.DockerClient, action string, project *compose.ProjectState) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+
+		ctx := context.Background()
+		cli := docker.Client
+		progress := func(step string) {
+			updates <- ProjectProgressMsg{Step: step}
+		}
+
+		var err error
+		switch action {
+		case "up":
+			err = compose.Up(ctx, cli, project.Project, progress)
+		case "down":
+			err = compose.Down(ctx, cli, project.Name, progress)
+		case "restart":
+			err = compose.Restart(ctx, cli, project.Name, progress)
+		case "stop":
+			err = compose.Stop(ctx, cli, project.Name, progress)
+		case "pull":
+			err = compose.Pull(ctx, cli, project.Project, progress)
+		case "recreate":
+			err = recreateOutOfDate(ctx, docker, project.Project, progress)
+		default:
+			err = fmt.Errorf("unknown action %s", action)
+		}
+
+		updates <- ProjectDoneMsg{Action: action, Project: project.Name, Error: err}
+	}()
+
+	return updates
+}
+
+// startAction switches to the progress view and runs a project operation
+func (m *ProjectModel) startAction(action string) tea.Cmd {
+	if m.selected == nil {
+		return nil
+	}
+	if (action == "up" || action == "pull" || action == "recreate") && m.selected.Project == nil {
+		m.steps = []string{StyleError.Render(fmt.Sprintf("Cannot %s %s: compose files are not available", action, m.selected.Name))}
+		if m.selected.LoadError != nil {
+			m.steps = append(m.steps, StyleError.Render(m.selected.LoadError.Error()))
+		}
+		m.viewport.SetContent(strings.Join(m.steps, "\n"))
+		m.previousState = m.state
+		m.state = "progress"
+		return nil
+	}
+
+	m.action = action
+	m.steps = nil
+	m.running = true
+	m.viewport.SetContent("")
+	m.previousState = m.state
+	m.state = "progress"
+	m.progress = runProjectAction(m.docker, action, m.selected)
+	return tea.Batch(waitForUpdate(m.progress), m.spin.Tick)
+}
+
+// selectedProject returns the project under the cursor
+func (m *ProjectModel) selectedProject() *compose.ProjectState {
+	if item, ok := m.projectList.SelectedItem().(ProjectItem); ok {
+		return item.project
+	}
+	return nil
+}
+
+// showDetails fills the service list for the selected project
+func (m *ProjectModel) showDetails() tea.Cmd {
+	items := make([]list.Item, 0, len(m.selected.Services))
+	for _, svc := range m.selected.Services {
+		items = append(items, ServiceItem{service: svc})
+	}
+	m.serviceList.Title = fmt.Sprintf("Project: %s", m.selected.Name)
+	return m.serviceList.SetItems(items)
+}
+
+// handleActionKey starts the project action bound to a key
+func (m *ProjectModel) handleActionKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keyMap.Up):
+		return m.startAction("up"), true
+	case key.Matches(msg, m.keyMap.Restart):
+		return m.startAction("restart"), true
+	case key.Matches(msg, m.keyMap.Stop):
+		return m.startAction("stop"), true
+	case key.Matches(msg, m.keyMap.Pull):
+		return m.startAction("pull"), true
+	case key.Matches(msg, m.keyMap.Down):
+		m.confirmMsg = fmt.Sprintf("Are you sure you want to take down project %s? Its containers and networks will be removed.", m.selected.Name)
+		m.confirmAction = "down"
+		m.previousState = m.state
+		m.state = "confirm"
+		return nil, true
+	case key.Matches(msg, m.keyMap.Logs):
+		m.previousState = m.state
+		m.state = "logs"
+		m.loading = true
+		return tea.Batch(m.fetchLogs(m.selected.Name), m.spin.Tick), true
+	case key.Matches(msg, m.keyMap.Drift):
+		return m.openDrift(), true
+	}
+	return nil, false
+}
+
+// openDrift switches to the drift view of the selected project
+func (m *ProjectModel) openDrift() tea.Cmd {
+	m.previousState = m.state
+	m.drifts = nil
+	if m.selected.Project == nil {
+		msg := fmt.Sprintf("Cannot compare %s: compose files are not available", m.selected.Name)
+		if m.selected.LoadError != nil {
+			msg += "\n" + m.selected.LoadError.Error()
+		}
+		m.viewport.SetContent(StyleError.Render(msg))
+		m.state = "drift"
+		return nil
+	}
+
+	m.state = "drift"
+	m.loading = true
+	return tea.Batch(m.fetchDrift(m.selected.Project), m.spin.Tick)
+}
+
+// openScale prompts for the replica count of the service under the cursor
+func (m *ProjectModel) openScale() tea.Cmd {
+	item, ok := m.serviceList.SelectedItem().(ServiceItem)
+	if !ok {
+		return nil
+	}
+	m.scaleService = item.service.Name
+	m.scaleErr = ""
+	m.scaleInput.SetValue(strconv.Itoa(len(item.service.Containers)))
+	m.scaleInput.CursorEnd()
+	m.state = "scale"
+	return m.scaleInput.Focus()
+}
+
+// updateScale handles keys while the replica count prompt is open
+func (m *ProjectModel) updateScale(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		replicas, err := strconv.Atoi(strings.TrimSpace(m.scaleInput.Value()))
+		if err != nil || replicas < 0 {
+			m.scaleErr = "Enter a number of replicas, 0 or more"
+			return nil
+		}
+		m.scaleInput.Blur()
+
+		m.action = fmt.Sprintf("scale %s", m.scaleService)
+		m.steps = nil
+		m.running = true
+		m.viewport.SetContent("")
+		m.previousState = "detail"
+		m.state = "progress"
+		m.progress = scaleServiceAction(m.docker, m.selected, m.scaleService, replicas)
+		return tea.Batch(waitForUpdate(m.progress), m.spin.Tick)
+
+	case "esc":
+		m.scaleInput.Blur()
+		m.state = "detail"
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.scaleInput, cmd = m.scaleInput.Update(msg)
+	return cmd
+}
+
+// CapturingInput reports whether a text input currently has focus
+func (m *ProjectModel) CapturingInput() bool {
+	switch m.state {
+	case "scale":
+		return true
+	case "progress":
+		// Leaving mid-operation could leave services half created or removed
+		return m.running
+	case "list":
+		return m.projectList.SettingFilter()
+	}
+	return false
+}
+
+// Update handles messages and updates the model
+func (m *ProjectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch m.state {
+		case "list":
+			if m.projectList.SettingFilter() {
+				break
+			}
+
+			switch {
+			case key.Matches(msg, m.keyMap.Back), key.Matches(msg, m.keyMap.MainMenu):
+				return m, func() tea.Msg {
+					return ReturnToMainMsg{}
+				}
+
+			case key.Matches(msg, m.keyMap.Refresh):
+				m.loading = true
+				m.error = nil
+				return m, tea.Batch(m.fetchProjects(), m.spin.Tick)
+
+			case key.Matches(msg, m.keyMap.Details):
+				if m.selected = m.selectedProject(); m.selected != nil {
+					m.state = "detail"
+					return m, m.showDetails()
+				}
+				return m, nil
+			}
+
+			if m.selected = m.selectedProject(); m.selected != nil {
+				if cmd, ok := m.handleActionKey(msg); ok {
+					return m, cmd
+				}
+			}
+
+		case "detail":
+			switch {
+			case key.Matches(msg, m.keyMap.Back):
+				m.state = "list"
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.Refresh):
+				m.loading = true
+				return m, tea.Batch(m.fetchProjects(), m.spin.Tick)
+
+			case key.Matches(msg, m.keyMap.Scale):
+				return m, m.openScale()
+			}
+
+			if cmd, ok := m.handleActionKey(msg); ok {
+				return m, cmd
+			}
+
+			var cmd tea.Cmd
+			m.serviceList, cmd = m.serviceList.Update(msg)
+			return m, cmd
+
+		case "progress":
+			if key.Matches(msg, m.keyMap.Back) && !m.running {
+				m.state = m.previousState
+				m.loading = true
+				cmds = append(cmds, m.fetchProjects(), m.spin.Tick)
+				if m.state == "drift" {
+					cmds = append(cmds, m.fetchDrift(m.selected.Project))
+				}
+				return m, tea.Batch(cmds...)
+			}
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+
+		case "logs":
+			switch {
+			case key.Matches(msg, m.keyMap.Back):
+				m.state = m.previousState
+				return m, nil
+			case key.Matches(msg, m.keyMap.Refresh):
+				m.loading = true
+				return m, tea.Batch(m.fetchLogs(m.selected.Name), m.spin.Tick)
+			}
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+
+		case "scale":
+			return m, m.updateScale(msg)
+
+		case "drift":
+			switch {
+			case key.Matches(msg, m.keyMap.Back):
+				m.state = m.previousState
+				return m, nil
+			case key.Matches(msg, m.keyMap.Refresh):
+				return m, m.openDrift()
+			case key.Matches(msg, m.keyMap.Recreate):
+				services := outOfDateServices(m.drifts)
+				if m.loading || len(services) == 0 {
+					return m, nil
+				}
+				m.confirmMsg = fmt.Sprintf("Recreate out-of-date services of %s from the compose files?\n\n%s\n\nTheir containers will be removed and created again.",
+					m.selected.Name, strings.Join(services, ", "))
+				m.confirmAction = "recreate"
+				m.previousState = "drift"
+				m.state = "confirm"
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+
+		case "confirm":
+			switch msg.String() {
+			case "y", "Y":
+				m.state = m.previousState
+				return m, m.startAction(m.confirmAction)
+			case "n", "N", "esc":
+				m.state = m.previousState
+			}
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resize()
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading || m.running {
+			var cmd tea.Cmd
+			m.spin, cmd = m.spin.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case ProjectListMsg:
+		m.loading = false
+		if msg.Error != nil {
+			m.error = msg.Error
+			return m, nil
+		}
+
+		m.projects = msg.Projects
+		items := make([]list.Item, 0, len(msg.Projects))
+		for _, project := range msg.Projects {
+			items = append(items, ProjectItem{project: project})
+		}
+		cmds = append(cmds, m.projectList.SetItems(items))
+
+		// Keep the detail view pointing at fresh data
+		if m.selected != nil {
+			found := false
+			for _, project := range msg.Projects {
+				if project.Name == m.selected.Name {
+					m.selected = project
+					found = true
+					break
+				}
+			}
+			if !found && m.state == "detail" {
+				m.state = "list"
+			}
+			if found {
+				cmds = append(cmds, m.showDetails())
+			}
+		}
+		return m, tea.Batch(cmds...)
+
+	case ProjectLogsMsg:
+		m.loading = false
+		if msg.Error != nil {
+			m.viewport.SetContent(StyleError.Render(fmt.Sprintf("Error fetching logs: %v", msg.Error)))
+			return m, nil
+		}
+		if msg.Logs == "" {
+			msg.Logs = "No logs available"
+		}
+		m.viewport.SetContent(msg.Logs)
+		m.viewport.GotoBottom()
+		return m, nil
+
+	case ProjectDriftMsg:
+		m.loading = false
+		if msg.Error != nil {
+			m.viewport.SetContent(StyleError.Render(fmt.Sprintf("Error comparing project: %v", msg.Error)))
+			return m, nil
+		}
+		m.drifts = msg.Drifts
+		m.viewport.SetContent(renderDrift(msg.Drifts))
+		m.viewport.GotoTop()
+		return m, nil
+
+	case ProjectProgressMsg:
+		m.steps = append(m.steps, fmt.Sprintf("%s %s", time.Now().Format("15:04:05"), msg.Step))
+		m.viewport.SetContent(strings.Join(m.steps, "\n"))
+		m.viewport.GotoBottom()
+		return m, waitForUpdate(m.progress)
+
+	case ProjectDoneMsg:
+		m.running = false
+		if msg.Error != nil {
+			m.steps = append(m.steps, StyleError.Render(fmt.Sprintf("%s failed: %v", msg.Action, msg.Error)))
+		} else {
+			m.steps = append(m.steps, StyleSuccess.Render(fmt.Sprintf("%s of %s complete", msg.Action, msg.Project)))
+		}
+		m.viewport.SetContent(strings.Join(m.steps, "\n"))
+		m.viewport.GotoBottom()
+		return m, waitForUpdate(m.progress)
+	}
+
+	if m.state == "list" {
+		var cmd tea.Cmd
+		m.projectList, cmd = m.projectList.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// View renders the current view
+func (m *ProjectModel) View() string {
+	if m.loading && m.state != "logs" && m.state != "drift" {
+		return StyleMainLayout.Render(
+			lipgloss.JoinVertical(lipgloss.Center,
+				StyleTitle.Render("Compose Projects"),
+				fmt.Sprintf("%s Loading projects...", m.spin.View()),
+			),
+		)
+	}
+
+	if m.error != nil {
+		errorBox := StyleInfoBox.
+			BorderForeground(ColorError).
+			Render(StyleError.Render(fmt.Sprintf("Error: %v", m.error)))
+
+		help := "Press r to retry, esc to go back"
+		return StyleMainLayout.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				StyleTitle.Render("Compose Projects"),
+				errorBox,
+				help,
+			),
+		)
+	}
+
+	var content string
+	switch m.state {
+	case "list":
+		if len(m.projects) == 0 {
+			content = lipgloss.JoinVertical(lipgloss.Left,
+				StyleTitle.Render("Compose Projects"),
+				"",
+				StyleInfoBox.Render("No compose projects found. Projects are discovered from container labels."),
+				StyleFooter.Render("Press r to refresh, esc to go back"),
+			)
+		} else {
+			content = m.projectList.View()
+		}
+
+	case "detail":
+		header := ""
+		if m.selected.LoadError != nil {
+			header = StyleWarning.Render(fmt.Sprintf("Compose file unreadable: %v", m.selected.LoadError))
+		} else if m.selected.WorkingDir != "" {
+			header = StyleSubtle.Render(m.selected.WorkingDir)
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			header,
+			m.serviceList.View(),
+		)
+
+	case "progress":
+		footer := "Press esc to go back"
+		if m.running {
+			footer = fmt.Sprintf("%s Running %s...", m.spin.View(), m.action)
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render(fmt.Sprintf("Project %s: %s", m.selected.Name, m.action)),
+			m.viewport.View(),
+			StyleFooter.Render(footer),
+		)
+
+	case "logs":
+		footer := "Press r to refresh, esc to go back"
+		if m.loading {
+			footer = fmt.Sprintf("%s Loading logs...", m.spin.View())
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render(fmt.Sprintf("Logs: %s", m.selected.Name)),
+			m.viewport.View(),
+			StyleFooter.Render(footer),
+		)
+
+	case "drift":
+		footer := "Press r to refresh, esc to go back"
+		if len(outOfDateServices(m.drifts)) > 0 {
+			footer = "R: Recreate out-of-date services • r: Refresh • esc: Back"
+		}
+		if m.loading {
+			footer = fmt.Sprintf("%s Comparing with compose files...", m.spin.View())
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render(fmt.Sprintf("Drift: %s", m.selected.Name)),
+			m.viewport.View(),
+			StyleFooter.Render(footer),
+		)
+
+	case "scale":
+		lines := []string{
+			fmt.Sprintf("Scale service %s of project %s", m.scaleService, m.selected.Name),
+			"",
+			fmt.Sprintf("%s %s", m.scaleInput.PromptStyle.Render("Replicas:"), m.scaleInput.View()),
+		}
+		if m.scaleErr != "" {
+			lines = append(lines, StyleError.Render(m.scaleErr))
+		}
+		lines = append(lines, "", "New replicas clone an existing one • Enter: Scale • Esc: Cancel")
+
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Scale Service"),
+			"",
+			StyleInfoBox.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		)
+
+	case "confirm":
+		confirmBox := StyleInfoBox.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				m.confirmMsg,
+				"",
+				"Press (y)es to confirm or (n)o to cancel",
+			),
+		)
+
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			StyleTitle.Render("Confirm Action"),
+			"",
+			confirmBox,
+		)
+	}
+
+	if m.state == "list" || m.state == "detail" {
+		help := "enter: Services • u: Up • d: Down • t: Restart • s: Stop • p: Pull • l: Logs • D: Drift • r: Refresh • esc: Back • m: Main menu"
+		if m.state == "detail" {
+			help = "S: Scale service • u: Up • d: Down • t: Restart • s: Stop • p: Pull • l: Logs • D: Drift • r: Refresh • esc: Back • m: Main menu"
+		}
+		helpText := StyleHelp.Render(help)
+		content = lipgloss.JoinVertical(lipgloss.Left, content, "", helpText)
+	}
+
+	return StyleMainLayout.Render(content)
+}
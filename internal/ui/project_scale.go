@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/client"
+	"github.com/Gostatsog/dockerNav/internal/compose"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// scaleServiceAction runs scaleService in the background. Progress is sent
+// on the returned channel, which is closed once a ProjectDoneMsg has been
+// delivered.
+func scaleServiceAction(docker *client.DockerClient, project *compose.ProjectState, service string, replicas int) <-chan tea.Msg {
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+
+		step := func(format string, args ...interface{}) {
+			updates <- ProjectProgressMsg{Step: fmt.Sprintf(format, args...)}
+		}
+
+		err := scaleService(docker, project, service, replicas, step)
+		updates <- ProjectDoneMsg{
+			Action:  fmt.Sprintf("scale of %s to %d", service, replicas),
+			Project: project.Name,
+			Error:   err,
+		}
+	}()
+
+	return updates
+}
+
+// scaleService changes the number of replicas of a compose service. New
+// replicas are clones of an existing one with the next container number,
+// excess replicas are removed starting with the highest number. A service
+// without containers is created from the compose file when it is available.
+func scaleService(docker *client.DockerClient, project *compose.ProjectState, service string, replicas int, step func(string, ...interface{})) error {
+	ctx := context.Background()
+	cli := docker.Client
+
+	containers, err := compose.ServiceContainers(ctx, cli, project.Name, service)
+	if err != nil {
+		return err
+	}
+	step("%s has %d replicas, scaling to %d", service, len(containers), replicas)
+
+	// Remove from the end so the remaining numbers stay contiguous
+	for i := len(containers) - 1; i >= replicas; i-- {
+		name := compose.DisplayName(containers[i])
+		if containers[i].State == "running" {
+			step("%s: stopping", name)
+			if err := cli.ContainerStop(ctx, containers[i].ID, container.StopOptions{}); err != nil {
+				return fmt.Errorf("stop %s: %w", name, err)
+			}
+		}
+		step("%s: removing", name)
+		if err := cli.ContainerRemove(ctx, containers[i].ID, container.RemoveOptions{}); err != nil {
+			return fmt.Errorf("remove %s: %w", name, err)
+		}
+	}
+
+	if len(containers) >= replicas {
+		return nil
+	}
+
+	if len(containers) == 0 {
+		if project.Project == nil {
+			return fmt.Errorf("%s has no replica to clone and its compose file is not available", service)
+		}
+		progress := func(s string) { step("%s", s) }
+		if err := compose.EnsureImage(ctx, cli, project.Project.Services[service].Image, progress); err != nil {
+			return err
+		}
+		for number := 1; number <= replicas; number++ {
+			if _, err := compose.CreateReplica(ctx, cli, project.Project, service, number, progress); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Prefer a running replica as the template
+	template := containers[0]
+	next := 0
+	for _, c := range containers {
+		if c.State == "running" && template.State != "running" {
+			template = c
+		}
+		if n := compose.ContainerNumber(c); n > next {
+			next = n
+		}
+	}
+
+	for i := len(containers); i < replicas; i++ {
+		next++
+		if err := cloneReplica(ctx, docker, template.ID, next, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneReplica creates and starts a copy of a service container carrying
+// the given container number
+func cloneReplica(ctx context.Context, docker *client.DockerClient, templateID string, number int, step func(string, ...interface{})) error {
+	cli := docker.Client
+
+	tmpl, err := cli.ContainerInspect(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
+	config := cloneConfig(tmpl.Config, tmpl.ID)
+	config.Labels = map[string]string{}
+	for k, v := range tmpl.Config.Labels {
+		config.Labels[k] = v
+	}
+	config.Labels[compose.LabelNumber] = strconv.Itoa(number)
+
+	name := replicaName(strings.TrimPrefix(tmpl.Name, "/"), tmpl.Config.Labels, number)
+
+	primary, extra := cloneEndpoints(tmpl.HostConfig.NetworkMode, tmpl.NetworkSettings)
+	// Static addresses belong to the template and would collide
+	for _, ep := range primary.EndpointsConfig {
+		ep.IPAMConfig = nil
+		ep.MacAddress = ""
+	}
+	for _, ep := range extra {
+		ep.IPAMConfig = nil
+		ep.MacAddress = ""
+	}
+
+	// A fixed host port can only be published by one replica, so the clone
+	// gets a port the daemon picks instead
+	hostConfig := *tmpl.HostConfig
+	hostConfig.PortBindings = nat.PortMap{}
+	for port, bindings := range tmpl.HostConfig.PortBindings {
+		for _, b := range bindings {
+			hostConfig.PortBindings[port] = append(hostConfig.PortBindings[port], nat.PortBinding{HostIP: b.HostIP})
+		}
+	}
+
+	step("%s: creating", name)
+	created, err := cli.ContainerCreate(ctx, &config, &hostConfig, primary, nil, name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	// A replica that cannot run is not left behind to be counted
+	discard := func(cause error) error {
+		_ = cli.ContainerRemove(ctx, created.ID, container.RemoveOptions{Force: true})
+		return cause
+	}
+
+	for netName, endpoint := range extra {
+		step("%s: connecting to network %s", name, netName)
+		if err := cli.NetworkConnect(ctx, netName, created.ID, endpoint); err != nil {
+			return discard(fmt.Errorf("connect %s to %s: %w", name, netName, err))
+		}
+	}
+
+	step("%s: starting", name)
+	if err := cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return discard(fmt.Errorf("start %s: %w", name, err))
+	}
+	return nil
+}
+
+// replicaName derives the name of a new replica from the template name,
+// replacing its trailing container number
+func replicaName(templateName string, labels map[string]string, number int) string {
+	suffix := labels[compose.LabelNumber]
+	for _, sep := range []string{"-", "_"} {
+		if suffix != "" && strings.HasSuffix(templateName, sep+suffix) {
+			return strings.TrimSuffix(templateName, suffix) + strconv.Itoa(number)
+		}
+	}
+	return fmt.Sprintf("%s-%s-%d", labels[compose.LabelProject], labels[compose.LabelService], number)
+}
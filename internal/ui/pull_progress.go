@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-units"
+)
+
+// decodePullStream decodes the JSON progress stream of a pull or push and
+// calls send for every message. An error reported in the stream is returned.
+func decodePullStream(r io.Reader, send func(jsonmessage.JSONMessage)) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		send(msg)
+	}
+}
+
+// speedWindow is how far back transfer samples are kept to compute the speed
+const speedWindow = 5 * time.Second
+
+// layerProgress is the latest status of a single layer
+type layerProgress struct {
+	id          string
+	status      string
+	current     int64
+	total       int64
+	size        int64 // compressed size, known once the transfer starts
+	transferred int64
+}
+
+// transferSample is the number of bytes transferred at a point in time
+type transferSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// pullProgress tracks the per-layer status of an image pull or push
+type pullProgress struct {
+	layers  []*layerProgress
+	byID    map[string]*layerProgress
+	status  string // latest message not tied to a layer
+	bar     progress.Model
+	samples []transferSample
+}
+
+// newPullProgress creates an empty pull progress tracker
+func newPullProgress() *pullProgress {
+	return &pullProgress{
+		byID: map[string]*layerProgress{},
+		bar:  progress.New(progress.WithDefaultGradient(), progress.WithWidth(30), progress.WithoutPercentage()),
+	}
+}
+
+// update records a message from the pull stream
+func (p *pullProgress) update(msg jsonmessage.JSONMessage) {
+	if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from") {
+		p.status = strings.TrimSpace(msg.Status + " " + msg.ID)
+		return
+	}
+
+	layer, ok := p.byID[msg.ID]
+	if !ok {
+		layer = &layerProgress{id: msg.ID}
+		p.byID[msg.ID] = layer
+		p.layers = append(p.layers, layer)
+	}
+	layer.status = msg.Status
+	if msg.Progress != nil {
+		layer.current = msg.Progress.Current
+		layer.total = msg.Progress.Total
+	}
+
+	switch msg.Status {
+	case "Downloading", "Pushing":
+		if msg.Progress != nil && msg.Progress.Total > 0 {
+			layer.size = msg.Progress.Total
+			layer.transferred = msg.Progress.Current
+		}
+	case "Verifying Checksum", "Download complete", "Extracting", "Pull complete", "Pushed":
+		layer.transferred = layer.size
+	}
+	p.record(time.Now())
+}
+
+// record samples the transferred bytes, keeping one sample older than the
+// window as the baseline of the speed
+func (p *pullProgress) record(now time.Time) {
+	current, _ := p.transferred()
+	if n := len(p.samples); n > 0 && p.samples[n-1].bytes == current {
+		return
+	}
+	p.samples = append(p.samples, transferSample{at: now, bytes: current})
+	for len(p.samples) > 2 && now.Sub(p.samples[1].at) >= speedWindow {
+		p.samples = p.samples[1:]
+	}
+}
+
+// transferred returns the bytes transferred and the total size of the
+// layers whose size is known
+func (p *pullProgress) transferred() (current, total int64) {
+	for _, layer := range p.layers {
+		current += layer.transferred
+		total += layer.size
+	}
+	return current, total
+}
+
+// sizing reports whether some layers have not started transferring, so
+// their size and the total are not known yet
+func (p *pullProgress) sizing() bool {
+	for _, layer := range p.layers {
+		switch layer.status {
+		case "Pulling fs layer", "Waiting", "Preparing":
+			if layer.size == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// speed returns the transfer rate in bytes per second over the last samples
+func (p *pullProgress) speed() float64 {
+	if len(p.samples) < 2 {
+		return 0
+	}
+	first, last := p.samples[0], p.samples[len(p.samples)-1]
+	elapsed := last.at.Sub(first.at).Seconds()
+	if elapsed < 0.5 {
+		return 0
+	}
+	return float64(last.bytes-first.bytes) / elapsed
+}
+
+// percent returns the overall fraction transferred
+func (p *pullProgress) percent() float64 {
+	current, total := p.transferred()
+	if total == 0 {
+		return 0
+	}
+	return float64(current) / float64(total)
+}
+
+// Summary renders the overall bytes, speed and estimated time left
+func (p *pullProgress) Summary() string {
+	current, total := p.transferred()
+	if total == 0 {
+		return ""
+	}
+
+	parts := []string{fmt.Sprintf("%s/%s", units.HumanSize(float64(current)), units.HumanSize(float64(total)))}
+	speed := p.speed()
+	if speed > 0 {
+		parts = append(parts, units.HumanSize(speed)+"/s")
+	}
+	switch {
+	case current >= total && !p.sizing():
+	case p.sizing():
+		parts = append(parts, "sizing remaining layers")
+	case speed > 0:
+		eta := time.Duration(float64(total-current) / speed * float64(time.Second))
+		parts = append(parts, "ETA "+eta.Round(time.Second).String())
+	}
+	return strings.Join(parts, " • ")
+}
+
+// View renders a line per layer with a progress bar for transfers
+func (p *pullProgress) View() string {
+	lines := make([]string, 0, len(p.layers)+1)
+	for _, layer := range p.layers {
+		line := fmt.Sprintf("%-12s %-20s", layer.id, layer.status)
+		if layer.total > 0 && (layer.status == "Downloading" || layer.status == "Extracting" || layer.status == "Pushing" ||
+			layer.status == "Loading layer") {
+			percent := float64(layer.current) / float64(layer.total)
+			line += fmt.Sprintf(" %s %s/%s", p.bar.ViewAs(percent),
+				units.HumanSize(float64(layer.current)), units.HumanSize(float64(layer.total)))
+		}
+		lines = append(lines, line)
+	}
+	if p.status != "" {
+		lines = append(lines, StyleSubtle.Render(p.status))
+	}
+	if summary := p.Summary(); summary != "" {
+		lines = append(lines, "", fmt.Sprintf("Total %s %s", p.bar.ViewAs(p.percent()), summary))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Gostatsog/dockerNav/internal/registryauth"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// RegistryLoginMsg carries the result of a registry login
+type RegistryLoginMsg struct {
+	Server string
+	Status string
+	Error  error
+}
+
+// validateRequired rejects an empty value
+func validateRequired(name string) func(string) error {
+	return func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+}
+
+// startLogin shows the registry login form
+func (m *ImageModel) startLogin() tea.Cmd {
+	m.loginPassword = ""
+	m.loginErr = ""
+	m.loggingIn = false
+	m.initLoginForm()
+	m.state = "login"
+	return m.loginForm.Init()
+}
+
+// initLoginForm builds the login form around the current values
+func (m *ImageModel) initLoginForm() {
+	m.loginForm = huh.NewForm(huh.NewGroup(
+		huh.NewInput().
+			Title("Registry").
+			Description("Host of the registry, e.g., localhost:5000; empty for Docker Hub").
+			Placeholder("docker.io").
+			Value(&m.loginServer),
+
+		huh.NewInput().
+			Title("Username").
+			Value(&m.loginUser).
+			Validate(validateRequired("a username")),
+
+		huh.NewInput().
+			Title("Password or Access Token").
+			EchoMode(huh.EchoModePassword).
+			Value(&m.loginPassword).
+			Validate(validateRequired("a password")),
+	)).WithWidth(m.width - 4).WithShowHelp(true)
+}
+
+// login checks the credentials with the registry through the daemon and
+// stores them in the Docker CLI config
+func (m *ImageModel) login() tea.Cmd {
+	auth := registry.AuthConfig{
+		ServerAddress: registryauth.NormalizeServer(m.loginServer),
+		Username:      m.loginUser,
+		Password:      m.loginPassword,
+	}
+
+	return func() tea.Msg {
+		resp, err := m.docker.Client.RegistryLogin(context.Background(), auth)
+		if err != nil {
+			return RegistryLoginMsg{Server: auth.ServerAddress, Error: err}
+		}
+
+		// Registries that issue an identity token want it instead of the password
+		if resp.IdentityToken != "" {
+			auth.Password = ""
+			auth.IdentityToken = resp.IdentityToken
+		}
+		if err := registryauth.Save(auth); err != nil {
+			return RegistryLoginMsg{
+				Server: auth.ServerAddress,
+				Error:  fmt.Errorf("logged in but could not store the credentials: %w", err),
+			}
+		}
+		return RegistryLoginMsg{Server: auth.ServerAddress, Status: resp.Status}
+	}
+}
+
+// updateLogin handles messages while the login form is shown
+func (m *ImageModel) updateLogin(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "esc" {
+			m.state = "list"
+			m.loginPassword = ""
+			return nil
+		}
+		if m.loggingIn {
+			return nil
+		}
+
+	case RegistryLoginMsg:
+		m.loggingIn = false
+		m.loginPassword = ""
+		if msg.Error != nil {
+			// Show the form again with the registry and username kept
+			m.loginErr = msg.Error.Error()
+			m.initLoginForm()
+			return m.loginForm.Init()
+		}
+		m.state = "list"
+		m.notice = fmt.Sprintf("%s: %s", msg.Server, msg.Status)
+		return nil
+	}
+
+	if m.loggingIn {
+		return nil
+	}
+	newForm, cmd := m.loginForm.Update(msg)
+	if form, ok := newForm.(*huh.Form); ok {
+		m.loginForm = form
+	}
+	if m.loginForm.State == huh.StateCompleted {
+		m.loggingIn = true
+		m.loginErr = ""
+		return tea.Batch(m.login(), m.spin.Tick)
+	}
+	return cmd
+}
+
+// renderLogin renders the registry login form
+func (m *ImageModel) renderLogin() string {
+	path, err := registryauth.ConfigPath()
+	if err != nil {
+		path = "the Docker config"
+	}
+
+	sections := []string{
+		StyleTitle.Render("Registry Login"),
+		StyleSubtle.Render(fmt.Sprintf("Credentials are stored in %s or its credential helper", path)),
+		"",
+	}
+	if m.loggingIn {
+		sections = append(sections, fmt.Sprintf("%s Logging in to %s...", m.spin.View(), registryauth.NormalizeServer(m.loginServer)))
+	} else {
+		sections = append(sections, m.loginForm.View())
+	}
+	if m.loginErr != "" {
+		sections = append(sections, "", StyleError.Render(m.loginErr))
+	}
+	sections = append(sections, "", StyleHelp.Render("Enter: Next • Esc: Cancel"))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}